@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	// rotationFrameRate is the nominal rate frame-locked mode assumes when converting degrees per second.
+	rotationFrameRate = 60.0
+	rotationSpeedStep = 1.25 // speed multiplier per keypress
+	rotationMaxSpeed  = 3600.0
+)
+
+// animationMode selects whether the spin advances per rendered frame or per wall-clock second.
+type animationMode int
+
+const (
+	animationTime animationMode = iota
+	animationFrame
+)
+
+// String returns the config name of the mode.
+func (m animationMode) String() string {
+	if m == animationFrame {
+		return "frame"
+	}
+	return "time"
+}
+
+// parseAnimationMode accepts the config names "time" and "frame".
+func parseAnimationMode(s string) (animationMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "time":
+		return animationTime, nil
+	case "frame":
+		return animationFrame, nil
+	}
+	return animationTime, fmt.Errorf("unknown animation mode %q (want time or frame)", s)
+}
+
+// rotationConfig holds the model spin settings loaded from the `rotation:` config block.
+type rotationConfig struct {
+	mode      animationMode
+	axis      mgl32.Vec3
+	degPerSec float32
+	clockwise bool // looking down the axis toward the origin
+}
+
+// defaultRotationConfig matches the original 45/60° per frame about Z, at 60 FPS.
+func defaultRotationConfig() rotationConfig {
+	return rotationConfig{mode: animationTime, axis: mgl32.Vec3{0, 0, 1}, degPerSec: 45}
+}
+
+// rotator accumulates the model spin so speed and direction changes don't make it jump.
+type rotator struct {
+	cfg   rotationConfig
+	prev  float32 // angle before the last step, for interpolation (radians)
+	angle float32 // radians
+}
+
+// newRotator starts at angle zero.
+func newRotator(cfg rotationConfig) *rotator {
+	return &rotator{cfg: cfg}
+}
+
+// step advances the angle by dt simulated seconds.
+func (r *rotator) step(dt float32) {
+	delta := mgl32.DegToRad(r.cfg.degPerSec * dt)
+	if r.cfg.clockwise {
+		delta = -delta
+	}
+	r.prev = r.angle
+	r.angle += delta
+	// Keep the angle small so float32 keeps its precision on long runs; prev moves with it so
+	// interpolation doesn't see the wrap.
+	wrapped := float32(math.Mod(float64(r.angle), 2*math.Pi))
+	if wrapped < 0 {
+		wrapped += 2 * math.Pi
+	}
+	r.prev += wrapped - r.angle
+	r.angle = wrapped
+}
+
+// model returns the spin as a model matrix, alpha of the way from the previous step to the current.
+func (r *rotator) model(alpha float32) mgl32.Mat4 {
+	return mgl32.HomogRotate3D(r.prev+(r.angle-r.prev)*alpha, r.cfg.axis)
+}
+
+// scaleSpeed multiplies the spin speed, clamped to a sane range.
+func (r *rotator) scaleSpeed(factor float32) {
+	r.cfg.degPerSec = mgl32.Clamp(r.cfg.degPerSec*factor, 0.1, rotationMaxSpeed)
+}
+
+// reverse flips the spin direction.
+func (r *rotator) reverse() {
+	r.cfg.clockwise = !r.cfg.clockwise
+}
+
+// toggleMode switches between frame-locked and wall-clock animation.
+func (r *rotator) toggleMode() {
+	if r.cfg.mode == animationTime {
+		r.cfg.mode = animationFrame
+	} else {
+		r.cfg.mode = animationTime
+	}
+}
+
+// describe summarizes the settings for log output.
+func (r *rotator) describe() string {
+	dir := "ccw"
+	if r.cfg.clockwise {
+		dir = "cw"
+	}
+	return fmt.Sprintf("%s mode, %.1f°/s %s about %v", r.cfg.mode, r.cfg.degPerSec, dir, r.cfg.axis)
+}
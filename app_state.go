@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+	"github.com/vulkan-go/glfw/v3.3/glfw"
+)
+
+// savedState is what survives between runs: window placement, camera pose, and view toggles.
+type savedState struct {
+	Window    savedWindow `json:"window"`
+	Camera    savedCamera `json:"camera"`
+	Paused    bool        `json:"paused"`
+	HUDHidden bool        `json:"hud_hidden"`
+}
+
+// savedWindow is the windowed geometry plus the mode to come back in.
+type savedWindow struct {
+	Mode    string `json:"mode"`
+	Monitor int    `json:"monitor"`
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Width   int    `json:"width"`
+	Height  int    `json:"height"`
+}
+
+// savedCamera is the orbit goal pose and the fly camera, plus which one was active.
+type savedCamera struct {
+	Mode     string     `json:"mode"`
+	Target   mgl32.Vec3 `json:"target"`
+	Yaw      float32    `json:"yaw"`
+	Pitch    float32    `json:"pitch"`
+	Distance float32    `json:"distance"`
+	FlyPos   mgl32.Vec3 `json:"fly_pos"`
+	FlyYaw   float32    `json:"fly_yaw"`
+	FlyPitch float32    `json:"fly_pitch"`
+	FlySpeed float32    `json:"fly_speed"`
+}
+
+// statePath is kube/state.json under the user config dir, or KUBE_STATE if set.
+func statePath() (string, error) {
+	if p := os.Getenv("KUBE_STATE"); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate state file: %w", err)
+	}
+	return filepath.Join(dir, "kube", "state.json"), nil
+}
+
+// loadSavedState reads the state file; a missing file is not an error and returns nil.
+func loadSavedState(path string) (*savedState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read state: %w", err)
+	}
+	var st savedState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return nil, fmt.Errorf("parse state %s: %w", path, err)
+	}
+	return &st, nil
+}
+
+// save writes the state atomically so a crash mid-write can't leave a truncated file.
+func (st *savedState) save(path string) error {
+	data, err := json.MarshalIndent(st, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write state: %w", err)
+	}
+	return nil
+}
+
+// snapshot records the windowed geometry, using the remembered one while fullscreen.
+func (s *windowState) snapshot(w *glfw.Window) savedWindow {
+	x, y, width, height := s.x, s.y, s.width, s.height
+	if s.mode == windowWindowed {
+		x, y = w.GetPos()
+		width, height = w.GetSize()
+	}
+	return savedWindow{Mode: s.mode.String(), Monitor: s.monitor, X: x, Y: y, Width: width, Height: height}
+}
+
+// restore puts a still-windowed window back where it was saved. A position that no longer
+// lands on a connected monitor is dropped so the window can't open off-screen.
+func (s *windowState) restore(w *glfw.Window, saved savedWindow) windowMode {
+	mode, err := parseWindowMode(saved.Mode)
+	if err != nil {
+		log.Printf("state: %v; starting windowed", err)
+	}
+	s.monitor = saved.Monitor
+	if saved.Width >= 64 && saved.Height >= 64 {
+		s.width, s.height = saved.Width, saved.Height
+		w.SetSize(s.width, s.height)
+	}
+	if onMonitor(saved.X+s.width/2, saved.Y+s.height/2) {
+		s.x, s.y, s.placed = saved.X, saved.Y, true
+		w.SetPos(s.x, s.y)
+	}
+	return mode
+}
+
+// onMonitor reports whether the desktop point x, y is on a connected monitor.
+func onMonitor(x, y int) bool {
+	for _, m := range glfw.GetMonitors() {
+		mx, my := m.GetPos()
+		if vm := m.GetVideoMode(); vm != nil && x >= mx && x < mx+vm.Width && y >= my && y < my+vm.Height {
+			return true
+		}
+	}
+	return false
+}
+
+// snapshot records the orbit goal and fly pose.
+func (r *cameraRig) snapshot() savedCamera {
+	mode := "orbit"
+	if r.mode == cameraFly {
+		mode = "fly"
+	}
+	g := r.orbit.goal
+	f := r.fly
+	return savedCamera{
+		Mode: mode, Target: g.target, Yaw: g.yaw, Pitch: g.pitch, Distance: g.distance,
+		FlyPos: f.pos, FlyYaw: f.yaw, FlyPitch: f.pitch, FlySpeed: f.speed,
+	}
+}
+
+// restore jumps both cameras to a saved pose, clamped to the current config's limits.
+func (r *cameraRig) restore(saved savedCamera) {
+	limit := mgl32.DegToRad(cameraPitchLimitDeg)
+	if saved.Distance > 0 {
+		pose := orbitPose{
+			target:   saved.Target,
+			yaw:      saved.Yaw,
+			pitch:    mgl32.Clamp(saved.Pitch, -limit, limit),
+			distance: mgl32.Clamp(saved.Distance, r.cfg.near*2, r.cfg.far*0.8),
+		}
+		r.orbit.current, r.orbit.goal = pose, pose
+	}
+	if saved.Mode == "fly" {
+		r.mode = cameraFly
+		r.fly.pos = saved.FlyPos
+		r.fly.yaw = saved.FlyYaw
+		r.fly.pitch = mgl32.Clamp(saved.FlyPitch, -limit, limit)
+		r.fly.hasMouse = false
+	}
+	if saved.FlySpeed > 0 {
+		r.fly.speed = mgl32.Clamp(saved.FlySpeed, 0.01, 1000)
+	}
+}
+
+// openSavedState returns the state file path and its contents when remember_state is on.
+// Problems are logged and start the session fresh; the path is still returned so a good
+// state gets written on exit.
+func openSavedState(remember bool) (string, *savedState) {
+	if !remember {
+		return "", nil
+	}
+	path, err := statePath()
+	if err != nil {
+		log.Printf("state: %v (not remembering state)", err)
+		return "", nil
+	}
+	st, err := loadSavedState(path)
+	if err != nil {
+		log.Printf("state: %v (starting fresh)", err)
+		return path, nil
+	}
+	return path, st
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+)
+
+// benchConfig holds the `bench:` config block; the run itself starts with `kube bench`.
+type benchConfig struct {
+	enabled bool
+	warmup  int    // frames rendered before measuring, to settle caches and clocks
+	frames  int    // measured frames
+	output  string // JSON report path
+}
+
+// defaultBenchConfig measures 1000 frames after 120 warmup frames.
+func defaultBenchConfig() benchConfig {
+	return benchConfig{warmup: 120, frames: 1000, output: "bench.json"}
+}
+
+// benchRun collects frame times while a benchmark runs.
+type benchRun struct {
+	cfg      benchConfig
+	frames   int // frames finished, warmup included
+	start    time.Time
+	measured time.Time // when the first measured frame began
+	cpu      []time.Duration
+	gpu      []time.Duration
+}
+
+// newBenchRun starts the clock on a benchmark.
+func newBenchRun(cfg benchConfig) *benchRun {
+	now := time.Now()
+	return &benchRun{cfg: cfg, start: now, measured: now, cpu: make([]time.Duration, 0, cfg.frames), gpu: make([]time.Duration, 0, cfg.frames)}
+}
+
+// measuring reports whether warmup is over.
+func (b *benchRun) measuring() bool {
+	return b.frames >= b.cfg.warmup
+}
+
+// endFrame records a finished frame's CPU time and reports whether the run is complete.
+func (b *benchRun) endFrame(cpu time.Duration) bool {
+	if b.measuring() {
+		b.cpu = append(b.cpu, cpu)
+	}
+	b.frames++
+	if b.frames == b.cfg.warmup {
+		b.measured = time.Now()
+	}
+	return b.frames >= b.cfg.warmup+b.cfg.frames
+}
+
+// recordGPU adds a retired frame's GPU time if frame, the bench frame it was recorded in,
+// is one of the measured ones. Results arrive frames late, so the frame is checked rather
+// than whether the run is measuring now.
+func (b *benchRun) recordGPU(frame int, d time.Duration) {
+	if frame >= b.cfg.warmup && frame < b.cfg.warmup+b.cfg.frames {
+		b.gpu = append(b.gpu, d)
+	}
+}
+
+// benchDevice describes what the benchmark ran on.
+type benchDevice struct {
+	Name          string `json:"name"`
+	VendorID      string `json:"vendor_id"`
+	DeviceID      string `json:"device_id"`
+	DriverVersion string `json:"driver_version"`
+	APIVersion    string `json:"api_version"`
+	PresentMode   string `json:"present_mode"`
+	Width         uint32 `json:"width"`
+	Height        uint32 `json:"height"`
+}
+
+// benchTimes summarizes a set of frame times in milliseconds.
+type benchTimes struct {
+	Samples int     `json:"samples"`
+	Avg     float64 `json:"avg_ms"`
+	Median  float64 `json:"median_ms"`
+	P95     float64 `json:"p95_ms"`
+	P99     float64 `json:"p99_ms"`
+	Min     float64 `json:"min_ms"`
+	Max     float64 `json:"max_ms"`
+}
+
+// benchReport is the JSON written at the end of a benchmark.
+type benchReport struct {
+	Time            time.Time   `json:"time"`
+	Device          benchDevice `json:"device"`
+	GOOS            string      `json:"goos"`
+	WarmupFrames    int         `json:"warmup_frames"`
+	Frames          int         `json:"frames"`
+	CPU             benchTimes  `json:"cpu"`
+	GPU             *benchTimes `json:"gpu,omitempty"` // absent when the GPU can't timestamp
+	TotalSeconds    float64     `json:"total_seconds"`
+	MeasuredFPS     float64     `json:"measured_fps"`
+	MeasuredSeconds float64     `json:"measured_seconds"`
+}
+
+// report summarizes the run.
+func (b *benchRun) report(device benchDevice) benchReport {
+	end := time.Now()
+	r := benchReport{
+		Time:            b.start,
+		Device:          device,
+		GOOS:            runtime.GOOS,
+		WarmupFrames:    b.cfg.warmup,
+		Frames:          len(b.cpu),
+		CPU:             summarizeBench(b.cpu),
+		TotalSeconds:    end.Sub(b.start).Seconds(),
+		MeasuredSeconds: end.Sub(b.measured).Seconds(),
+	}
+	if r.MeasuredSeconds > 0 {
+		r.MeasuredFPS = float64(len(b.cpu)) / r.MeasuredSeconds
+	}
+	if len(b.gpu) > 0 {
+		gpu := summarizeBench(b.gpu)
+		r.GPU = &gpu
+	}
+	return r
+}
+
+// summarizeBench computes the report statistics; an empty set is all zero.
+func summarizeBench(times []time.Duration) benchTimes {
+	if len(times) == 0 {
+		return benchTimes{}
+	}
+	s := summarizeFrameTimes(times)
+	sorted := append([]time.Duration(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return benchTimes{
+		Samples: len(sorted),
+		Avg:     ms(s.avg),
+		Median:  ms(percentile(sorted, 0.5)),
+		P95:     ms(percentile(sorted, 0.95)),
+		P99:     ms(s.p99),
+		Min:     ms(s.min),
+		Max:     ms(s.max),
+	}
+}
+
+// write saves the report as indented JSON.
+func (r benchReport) write(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode bench report: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("create %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write bench report: %w", err)
+	}
+	return nil
+}
+
+// logSummary prints the headline numbers.
+func (r benchReport) logSummary(path string) {
+	log.Printf("bench: %d frames on %s (%s, %dx%d) in %.1fs", r.Frames, r.Device.Name, r.Device.PresentMode, r.Device.Width, r.Device.Height, r.TotalSeconds)
+	log.Printf("bench: CPU avg %.3f median %.3f p95 %.3f p99 %.3f ms", r.CPU.Avg, r.CPU.Median, r.CPU.P95, r.CPU.P99)
+	if r.GPU != nil {
+		log.Printf("bench: GPU avg %.3f median %.3f p95 %.3f p99 %.3f ms", r.GPU.Avg, r.GPU.Median, r.GPU.P95, r.GPU.P99)
+	}
+	log.Printf("bench: report written to %s", path)
+}
+
+// formatVulkanVersion decodes a VK_MAKE_VERSION value.
+func formatVulkanVersion(v uint32) string {
+	return fmt.Sprintf("%d.%d.%d", v>>22, (v>>12)&0x3ff, v&0xfff)
+}
+
+// formatDriverVersion decodes driverVersion, which vendors pack differently; NVIDIA uses
+// 10.8.8.6 bits, most others follow the Vulkan version layout.
+func formatDriverVersion(vendorID, v uint32) string {
+	if vendorID == 0x10de {
+		return fmt.Sprintf("%d.%d.%d.%d", v>>22, (v>>14)&0xff, (v>>6)&0xff, v&0x3f)
+	}
+	return formatVulkanVersion(v)
+}
+
+// parseArgs reads the command line: no arguments runs the viewer, `bench [report.json]`
+// runs a benchmark and exits.
+func parseArgs(args []string) (bench bool, output string, err error) {
+	if len(args) == 0 {
+		return false, "", nil
+	}
+	if args[0] != "bench" || len(args) > 2 {
+		return false, "", fmt.Errorf("unknown arguments %q (usage: kube [bench [report.json]])", args)
+	}
+	if len(args) == 2 {
+		output = args[1]
+	}
+	return true, output, nil
+}
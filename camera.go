@@ -0,0 +1,331 @@
+package main
+
+import (
+	"math"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	cameraOrbitDegPerPixel = 0.3
+	cameraKeyOrbitDeg      = 5.0
+	cameraZoomStep         = 0.9 // distance multiplier per scroll notch
+	cameraPitchLimitDeg    = 89.0
+)
+
+// cameraConfig holds the projection, smoothing, and fly settings loaded from the `camera:` config block.
+type cameraConfig struct {
+	fovDeg           float32
+	near             float32
+	far              float32
+	damping          float32 // approach rate per second; 0 snaps immediately
+	flySpeed         float32 // units per second
+	mouseSensitivity float32 // degrees per pixel in fly mode
+}
+
+// defaultCameraConfig matches the original fixed 45° perspective.
+func defaultCameraConfig() cameraConfig {
+	return cameraConfig{fovDeg: 45, near: 0.1, far: 10, damping: 12, flySpeed: 2, mouseSensitivity: 0.1}
+}
+
+// cameraMode selects which camera drives the view.
+type cameraMode int
+
+const (
+	cameraOrbit cameraMode = iota
+	cameraFly
+)
+
+// cameraRig owns the orbit and fly cameras and routes view/projection to the active one.
+type cameraRig struct {
+	cfg   cameraConfig
+	mode  cameraMode
+	orbit *orbitCamera
+	fly   *flyCamera
+}
+
+// newCameraRig starts in orbit mode.
+func newCameraRig(cfg cameraConfig) *cameraRig {
+	return &cameraRig{cfg: cfg, orbit: newOrbitCamera(cfg), fly: newFlyCamera(cfg)}
+}
+
+// toggleMode switches between orbit and fly, handing over the current eye so the view doesn't jump.
+func (r *cameraRig) toggleMode() cameraMode {
+	if r.mode == cameraOrbit {
+		r.fly.lookFrom(r.orbit.eye(), r.orbit.current.target)
+		r.mode = cameraFly
+	} else {
+		r.fly.clearMovement()
+		r.mode = cameraOrbit
+	}
+	return r.mode
+}
+
+// reset returns the active camera to the starting view.
+func (r *cameraRig) reset() {
+	r.orbit.reset()
+	if r.mode == cameraFly {
+		r.fly.lookFrom(r.orbit.home.target.Add(r.orbit.home.offset()), r.orbit.home.target)
+	}
+}
+
+// update advances the active camera by dt seconds.
+func (r *cameraRig) update(dt float32) {
+	if r.mode == cameraFly {
+		r.fly.update(dt)
+		return
+	}
+	r.orbit.update(dt)
+}
+
+// view returns the active camera's view matrix.
+func (r *cameraRig) view() mgl32.Mat4 {
+	if r.mode == cameraFly {
+		return r.fly.view()
+	}
+	return r.orbit.view()
+}
+
+// projection returns a Vulkan-ready perspective matrix (Y flipped for Vulkan clip space).
+func (r *cameraRig) projection(aspect float32) mgl32.Mat4 {
+	proj := mgl32.Perspective(mgl32.DegToRad(r.cfg.fovDeg), aspect, r.cfg.near, r.cfg.far)
+	proj[5] *= -1
+	return proj
+}
+
+// dragMode says what a mouse drag is currently doing to the camera.
+type dragMode int
+
+const (
+	dragNone dragMode = iota
+	dragOrbit
+	dragPan
+)
+
+// orbitPose is the spherical camera placement around a target point (Z-up).
+type orbitPose struct {
+	target   mgl32.Vec3
+	yaw      float32 // radians around +Z, measured from +X
+	pitch    float32 // radians above the XY plane
+	distance float32
+}
+
+// orbitCamera orbits, pans, and zooms around a target. Input moves the goal pose and update
+// eases the current pose toward it.
+type orbitCamera struct {
+	cfg     cameraConfig
+	home    orbitPose
+	current orbitPose
+	goal    orbitPose
+	drag    dragMode
+	lastX   float64
+	lastY   float64
+}
+
+// newOrbitCamera starts at the original {3,3,3} eye looking at the origin.
+func newOrbitCamera(cfg cameraConfig) *orbitCamera {
+	eye := mgl32.Vec3{3, 3, 3}
+	home := orbitPose{
+		yaw:      float32(math.Atan2(float64(eye[1]), float64(eye[0]))),
+		pitch:    float32(math.Asin(float64(eye[2] / eye.Len()))),
+		distance: eye.Len(),
+	}
+	return &orbitCamera{cfg: cfg, home: home, current: home, goal: home}
+}
+
+// reset returns the camera to its starting pose, easing there like any other move.
+func (c *orbitCamera) reset() {
+	c.goal = c.home
+}
+
+// orbit rotates the goal pose by yaw/pitch degrees, clamping pitch short of the poles.
+func (c *orbitCamera) orbit(yawDeg, pitchDeg float32) {
+	c.goal.yaw += mgl32.DegToRad(yawDeg)
+	limit := mgl32.DegToRad(cameraPitchLimitDeg)
+	c.goal.pitch = mgl32.Clamp(c.goal.pitch+mgl32.DegToRad(pitchDeg), -limit, limit)
+}
+
+// pan slides the target across the view plane; dx/dy are fractions of the view height.
+func (c *orbitCamera) pan(dx, dy float32) {
+	forward := c.goal.offset().Mul(-1).Normalize()
+	right := forward.Cross(mgl32.Vec3{0, 0, 1}).Normalize()
+	up := right.Cross(forward)
+	// Scale by the visible height at the target so the point under the cursor tracks it.
+	height := 2 * c.goal.distance * float32(math.Tan(float64(mgl32.DegToRad(c.cfg.fovDeg))/2))
+	c.goal.target = c.goal.target.Add(right.Mul(-dx * height)).Add(up.Mul(dy * height))
+}
+
+// zoom moves toward (positive steps) or away from the target, staying inside the clip range.
+func (c *orbitCamera) zoom(steps float32) {
+	d := c.goal.distance * float32(math.Pow(cameraZoomStep, float64(steps)))
+	c.goal.distance = mgl32.Clamp(d, c.cfg.near*2, c.cfg.far*0.8)
+}
+
+// beginDrag starts an orbit or pan drag at the given cursor position.
+func (c *orbitCamera) beginDrag(mode dragMode, x, y float64) {
+	c.drag = mode
+	c.lastX, c.lastY = x, y
+}
+
+// endDrag stops any active drag.
+func (c *orbitCamera) endDrag() {
+	c.drag = dragNone
+}
+
+// cursorMoved applies an active drag; viewHeight converts pixels to pan distance.
+func (c *orbitCamera) cursorMoved(x, y float64, viewHeight int) {
+	dx, dy := float32(x-c.lastX), float32(y-c.lastY)
+	c.lastX, c.lastY = x, y
+	switch c.drag {
+	case dragOrbit:
+		c.orbit(-dx*cameraOrbitDegPerPixel, dy*cameraOrbitDegPerPixel)
+	case dragPan:
+		if viewHeight > 0 {
+			c.pan(dx/float32(viewHeight), dy/float32(viewHeight))
+		}
+	}
+}
+
+// update eases the current pose toward the goal with frame-rate independent damping.
+func (c *orbitCamera) update(dt float32) {
+	t := float32(1)
+	if c.cfg.damping > 0 {
+		t = 1 - float32(math.Exp(float64(-c.cfg.damping*dt)))
+	}
+	c.current.target = c.current.target.Add(c.goal.target.Sub(c.current.target).Mul(t))
+	c.current.yaw += (c.goal.yaw - c.current.yaw) * t
+	c.current.pitch += (c.goal.pitch - c.current.pitch) * t
+	c.current.distance += (c.goal.distance - c.current.distance) * t
+}
+
+// offset is the eye position relative to the target.
+func (p orbitPose) offset() mgl32.Vec3 {
+	cp := float32(math.Cos(float64(p.pitch)))
+	return mgl32.Vec3{
+		cp * float32(math.Cos(float64(p.yaw))),
+		cp * float32(math.Sin(float64(p.yaw))),
+		float32(math.Sin(float64(p.pitch))),
+	}.Mul(p.distance)
+}
+
+// eye returns the current world-space camera position.
+func (c *orbitCamera) eye() mgl32.Vec3 {
+	return c.current.target.Add(c.current.offset())
+}
+
+// view returns the Z-up look-at matrix for the current pose.
+func (c *orbitCamera) view() mgl32.Mat4 {
+	return mgl32.LookAtV(c.eye(), c.current.target, mgl32.Vec3{0, 0, 1})
+}
+
+// flyMove is the set of movement keys currently held in fly mode.
+type flyMove struct {
+	forward, back, left, right, up, down, boost bool
+}
+
+// flyCamera is a free-fly first-person camera: held keys move it and mouse deltas turn it.
+type flyCamera struct {
+	cfg      cameraConfig
+	pos      mgl32.Vec3
+	yaw      float32 // radians around +Z, measured from +X
+	pitch    float32
+	speed    float32
+	move     flyMove
+	analog   mgl32.Vec3 // gamepad movement (right, forward, up), each in [-1, 1]
+	lastX    float64
+	lastY    float64
+	hasMouse bool
+}
+
+// newFlyCamera creates a fly camera at the configured speed; lookFrom positions it.
+func newFlyCamera(cfg cameraConfig) *flyCamera {
+	return &flyCamera{cfg: cfg, speed: cfg.flySpeed}
+}
+
+// lookFrom places the camera at eye facing target and forgets the last cursor position.
+func (f *flyCamera) lookFrom(eye, target mgl32.Vec3) {
+	dir := target.Sub(eye)
+	f.pos = eye
+	f.yaw = float32(math.Atan2(float64(dir[1]), float64(dir[0])))
+	f.pitch = float32(math.Atan2(float64(dir[2]), math.Hypot(float64(dir[0]), float64(dir[1]))))
+	f.hasMouse = false
+}
+
+// clearMovement releases all held movement keys and centers the gamepad stick.
+func (f *flyCamera) clearMovement() {
+	f.move = flyMove{}
+	f.analog = mgl32.Vec3{}
+}
+
+// scaleSpeed multiplies the movement speed, e.g. from the scroll wheel.
+func (f *flyCamera) scaleSpeed(factor float32) {
+	f.speed = mgl32.Clamp(f.speed*factor, 0.01, 1000)
+}
+
+// cursorMoved turns the camera by the cursor delta. The first event after capture only
+// records the position so the view doesn't jump.
+func (f *flyCamera) cursorMoved(x, y float64) {
+	if !f.hasMouse {
+		f.lastX, f.lastY, f.hasMouse = x, y, true
+		return
+	}
+	dx, dy := float32(x-f.lastX), float32(y-f.lastY)
+	f.lastX, f.lastY = x, y
+	f.look(-dx*f.cfg.mouseSensitivity, -dy*f.cfg.mouseSensitivity)
+}
+
+// look turns the camera left (positive yaw) and up (positive pitch) by degrees.
+func (f *flyCamera) look(yawDeg, pitchDeg float32) {
+	f.yaw += mgl32.DegToRad(yawDeg)
+	limit := mgl32.DegToRad(cameraPitchLimitDeg)
+	f.pitch = mgl32.Clamp(f.pitch+mgl32.DegToRad(pitchDeg), -limit, limit)
+}
+
+// forward returns the unit view direction.
+func (f *flyCamera) forward() mgl32.Vec3 {
+	cp := float32(math.Cos(float64(f.pitch)))
+	return mgl32.Vec3{
+		cp * float32(math.Cos(float64(f.yaw))),
+		cp * float32(math.Sin(float64(f.yaw))),
+		float32(math.Sin(float64(f.pitch))),
+	}
+}
+
+// update moves along the held directions at speed units per second.
+func (f *flyCamera) update(dt float32) {
+	forward := f.forward()
+	right := forward.Cross(mgl32.Vec3{0, 0, 1}).Normalize()
+	up := mgl32.Vec3{0, 0, 1}
+	var dir mgl32.Vec3
+	axis := func(on bool, v mgl32.Vec3) {
+		if on {
+			dir = dir.Add(v)
+		}
+	}
+	axis(f.move.forward, forward)
+	axis(f.move.back, forward.Mul(-1))
+	axis(f.move.right, right)
+	axis(f.move.left, right.Mul(-1))
+	axis(f.move.up, up)
+	axis(f.move.down, up.Mul(-1))
+	if dir.Len() > 0 {
+		dir = dir.Normalize()
+	}
+	// Analog input adds partial speed; the total never exceeds full speed.
+	dir = dir.Add(right.Mul(f.analog[0])).Add(forward.Mul(f.analog[1])).Add(up.Mul(f.analog[2]))
+	if l := dir.Len(); l > 1 {
+		dir = dir.Mul(1 / l)
+	} else if l == 0 {
+		return
+	}
+	speed := f.speed
+	if f.move.boost {
+		speed *= 4
+	}
+	f.pos = f.pos.Add(dir.Mul(speed * dt))
+}
+
+// view returns the Z-up look-at matrix for the fly camera.
+func (f *flyCamera) view() mgl32.Mat4 {
+	return mgl32.LookAtV(f.pos, f.pos.Add(f.forward()), mgl32.Vec3{0, 0, 1})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"time"
+)
+
+const (
+	// clockMaxCatchUpSteps bounds the steps run in one frame so a stall doesn't snowball.
+	clockMaxCatchUpSteps = 8
+	clockScaleStep       = 2.0 // time scale multiplier per keypress
+	clockMinScale        = 1.0 / 64
+	clockMaxScale        = 16.0
+)
+
+// clockConfig holds the simulation timing loaded from the `clock:` config block.
+type clockConfig struct {
+	stepHz    float64 // fixed simulation steps per simulated second
+	timeScale float64 // simulated seconds per wall-clock second
+}
+
+// defaultClockConfig simulates at 60 Hz in real time.
+func defaultClockConfig() clockConfig {
+	return clockConfig{stepHz: 60, timeScale: 1}
+}
+
+// simClock turns wall-clock frame times into a whole number of fixed simulation steps.
+// Rendering interpolates between the last two steps using alpha, so the simulation is
+// deterministic regardless of frame rate.
+type simClock struct {
+	step        time.Duration
+	scale       float64
+	paused      bool
+	pendingStep bool // a single step was requested while paused
+	last        time.Time
+	accum       time.Duration // scaled time not yet simulated
+	steps       uint64        // simulation steps run so far
+	frames      uint64        // frames presented, paused or not
+}
+
+// newSimClock creates a running clock; the first tick only records the start time.
+func newSimClock(cfg clockConfig) *simClock {
+	return &simClock{
+		step:  time.Duration(float64(time.Second) / cfg.stepHz),
+		scale: cfg.timeScale,
+	}
+}
+
+// tick advances the clock to now and returns how many fixed steps to simulate.
+func (c *simClock) tick(now time.Time) int {
+	if c.last.IsZero() {
+		c.last = now
+		return 0
+	}
+	elapsed := now.Sub(c.last)
+	c.last = now
+	if c.paused {
+		if !c.pendingStep {
+			return 0
+		}
+		c.pendingStep = false
+		c.steps++
+		return 1
+	}
+	c.accum += time.Duration(float64(elapsed) * c.scale)
+	n := int(c.accum / c.step)
+	if n > clockMaxCatchUpSteps {
+		n = clockMaxCatchUpSteps
+		c.accum = 0
+	} else {
+		c.accum -= time.Duration(n) * c.step
+	}
+	c.steps += uint64(n)
+	return n
+}
+
+// replayTick applies a recorded tick instead of measuring wall-clock time. The next live
+// tick starts timing afresh.
+func (c *simClock) replayTick(steps int, accum time.Duration) {
+	if steps > 0 {
+		c.pendingStep = false
+	}
+	c.steps += uint64(steps)
+	c.accum = accum
+	c.last = time.Time{}
+}
+
+// running reports whether this frame advances the simulation: not paused, or a single step
+// was just taken.
+func (c *simClock) running(steps int) bool {
+	return !c.paused || steps > 0
+}
+
+// endFrame counts a frame whose simulation tick has been spent.
+func (c *simClock) endFrame() {
+	c.frames++
+}
+
+// stepSeconds is the fixed simulation step in seconds.
+func (c *simClock) stepSeconds() float32 {
+	return float32(c.step.Seconds())
+}
+
+// alpha is how far rendering sits between the previous and current simulation step.
+func (c *simClock) alpha() float32 {
+	return float32(float64(c.accum) / float64(c.step))
+}
+
+// seconds returns the total simulated time.
+func (c *simClock) seconds() float64 {
+	return float64(c.steps) * c.step.Seconds()
+}
+
+// renderSeconds is the simulated time rendering should show, including the interpolated
+// fraction of the next step. It stays float64 since float32 loses sub-frame precision
+// within a day.
+func (c *simClock) renderSeconds() float64 {
+	return c.seconds() + float64(c.alpha())*c.step.Seconds()
+}
+
+// togglePause freezes or resumes the simulation and reports the new state.
+func (c *simClock) togglePause() bool {
+	c.paused = !c.paused
+	c.pendingStep = false
+	return c.paused
+}
+
+// singleStep queues exactly one simulation step; it only has an effect while paused.
+func (c *simClock) singleStep() bool {
+	if !c.paused {
+		return false
+	}
+	c.pendingStep = true
+	return true
+}
+
+// scaleBy multiplies the time scale (slow motion below 1, fast forward above).
+func (c *simClock) scaleBy(factor float64) {
+	c.setScale(c.scale * factor)
+}
+
+// setScale sets the time scale, clamped to a usable range.
+func (c *simClock) setScale(scale float64) {
+	if scale < clockMinScale {
+		scale = clockMinScale
+	}
+	if scale > clockMaxScale {
+		scale = clockMaxScale
+	}
+	c.scale = scale
+}
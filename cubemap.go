@@ -0,0 +1,77 @@
+package main
+
+import "math"
+
+// cubeFaceDirection returns the (unnormalized) cube-map direction for face coordinates u, v in [-1, 1],
+// using the Vulkan face order +X, -X, +Y, -Y, +Z, -Z.
+func cubeFaceDirection(face int, u, v float64) (float64, float64, float64) {
+	switch face {
+	case 0:
+		return 1, -v, -u
+	case 1:
+		return -1, -v, u
+	case 2:
+		return u, 1, v
+	case 3:
+		return u, -1, -v
+	case 4:
+		return u, -v, 1
+	default:
+		return -u, -v, -1
+	}
+}
+
+// equirectToCubeFaces resamples an RGBA8 equirectangular panorama into six square RGBA8 cube faces.
+// Longitude wraps horizontally; latitude runs from +Y at the top row to -Y at the bottom.
+func equirectToCubeFaces(width, height uint32, pixels []byte, size uint32) [][]byte {
+	faces := make([][]byte, 6)
+	for face := range faces {
+		out := make([]byte, int(size*size*4))
+		for y := uint32(0); y < size; y++ {
+			v := (float64(y)+0.5)/float64(size)*2 - 1
+			for x := uint32(0); x < size; x++ {
+				u := (float64(x)+0.5)/float64(size)*2 - 1
+				dx, dy, dz := cubeFaceDirection(face, u, v)
+				lon := math.Atan2(dx, -dz)
+				lat := math.Atan2(dy, math.Hypot(dx, dz))
+				sx := (lon/(2*math.Pi) + 0.5) * float64(width)
+				sy := (0.5 - lat/math.Pi) * float64(height)
+				sampleBilinearRGBA(pixels, width, height, sx, sy, out[(y*size+x)*4:])
+			}
+		}
+		faces[face] = out
+	}
+	return faces
+}
+
+// sampleBilinearRGBA filters an RGBA8 image at texel-space coordinates, wrapping in X and clamping in Y.
+func sampleBilinearRGBA(pixels []byte, width, height uint32, sx, sy float64, dst []byte) {
+	sx -= 0.5
+	sy -= 0.5
+	x0 := math.Floor(sx)
+	y0 := math.Floor(sy)
+	fx := sx - x0
+	fy := sy - y0
+	w, h := int(width), int(height)
+	wrap := func(x int) int { return ((x % w) + w) % w }
+	clampY := func(y int) int {
+		if y < 0 {
+			return 0
+		}
+		if y >= h {
+			return h - 1
+		}
+		return y
+	}
+	xa, xb := wrap(int(x0)), wrap(int(x0)+1)
+	ya, yb := clampY(int(y0)), clampY(int(y0)+1)
+	for c := 0; c < 4; c++ {
+		p00 := float64(pixels[(ya*w+xa)*4+c])
+		p10 := float64(pixels[(ya*w+xb)*4+c])
+		p01 := float64(pixels[(yb*w+xa)*4+c])
+		p11 := float64(pixels[(yb*w+xb)*4+c])
+		top := p00 + (p10-p00)*fx
+		bottom := p01 + (p11-p01)*fx
+		dst[c] = uint8(math.Round(top + (bottom-top)*fy))
+	}
+}
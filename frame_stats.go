@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// frameHistorySize is how many recent frames the frame-time graph shows.
+const frameHistorySize = 240
+
+// frameBudget is one frame at 60 Hz, drawn as the graph's reference line.
+const frameBudget = time.Second / 60
+
+// frameTimeHistory is a ring buffer of the most recent CPU frame times.
+type frameTimeHistory struct {
+	samples [frameHistorySize]time.Duration
+	next    int
+	count   int
+}
+
+// record adds a frame time, overwriting the oldest once the buffer is full.
+func (h *frameTimeHistory) record(d time.Duration) {
+	h.samples[h.next] = d
+	h.next = (h.next + 1) % frameHistorySize
+	if h.count < frameHistorySize {
+		h.count++
+	}
+}
+
+// ordered returns the recorded frame times, oldest first.
+func (h *frameTimeHistory) ordered() []time.Duration {
+	out := make([]time.Duration, 0, h.count)
+	start := (h.next - h.count + frameHistorySize) % frameHistorySize
+	for i := 0; i < h.count; i++ {
+		out = append(out, h.samples[(start+i)%frameHistorySize])
+	}
+	return out
+}
+
+// frameTimeSummary describes a set of frame times.
+type frameTimeSummary struct {
+	min, avg, max, p99 time.Duration
+}
+
+// summarize computes min/avg/max/p99 over the recorded frames.
+func (h *frameTimeHistory) summarize() frameTimeSummary {
+	return summarizeFrameTimes(h.ordered())
+}
+
+// summarizeFrameTimes computes min/avg/max/p99; an empty set is all zero.
+func summarizeFrameTimes(times []time.Duration) frameTimeSummary {
+	if len(times) == 0 {
+		return frameTimeSummary{}
+	}
+	sorted := append([]time.Duration(nil), times...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	return frameTimeSummary{
+		min: sorted[0],
+		avg: total / time.Duration(len(sorted)),
+		max: sorted[len(sorted)-1],
+		p99: percentile(sorted, 0.99),
+	}
+}
+
+// percentile returns the nearest-rank p-quantile (0-1) of an ascending, non-empty slice.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
+
+// ms converts a duration to fractional milliseconds for display.
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// gpuPassTimes is how long the GPU spent on each part of one frame.
+type gpuPassTimes struct {
+	scene   time.Duration // clear plus mesh draws
+	skybox  time.Duration
+	overlay time.Duration
+	total   time.Duration
+}
+
+// timestampDuration converts the tick difference between two GPU timestamps to time. Only the
+// low validBits of each timestamp are meaningful, so the difference wraps at that width.
+func timestampDuration(start, end uint64, validBits uint32, period float32) time.Duration {
+	mask := ^uint64(0)
+	if validBits < 64 {
+		mask = uint64(1)<<validBits - 1
+	}
+	ticks := (end - start) & mask
+	return time.Duration(float64(ticks) * float64(period))
+}
+
+// pipelineStats are the pipeline statistics counters for one frame's mesh pass, in the order
+// Vulkan returns them for the enabled statistic bits.
+type pipelineStats struct {
+	inputVertices       uint64
+	inputPrimitives     uint64
+	vertexInvocations   uint64
+	clippingInvocations uint64
+	clippingPrimitives  uint64
+	fragmentInvocations uint64
+}
+
+// describe lays the counters out one per line for the HUD.
+func (s pipelineStats) describe() string {
+	return fmt.Sprintf("IA vertices     %d\nIA primitives   %d\nVS invocations  %d\nclip in / out   %d / %d\nFS invocations  %d",
+		s.inputVertices, s.inputPrimitives, s.vertexInvocations, s.clippingInvocations, s.clippingPrimitives, s.fragmentInvocations)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"log"
+	"math"
+	"strings"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+	"github.com/vulkan-go/glfw/v3.3/glfw"
+)
+
+// gamepadAxis names a continuous control driven by a joystick axis.
+type gamepadAxis int
+
+const (
+	padLookX gamepadAxis = iota // orbit, or turn in fly mode
+	padLookY
+	padMoveX // pan, or strafe in fly mode
+	padMoveY
+	padZoomIn // triggers: rest at -1, fully pressed at +1
+	padZoomOut
+	padAxisCount
+)
+
+var padAxisNames = [padAxisCount]string{"look_x", "look_y", "move_x", "move_y", "zoom_in", "zoom_out"}
+
+// gamepadConfig holds the `gamepad:` config block. Axis and button indices are the raw GLFW
+// joystick ones, since the bundled GLFW has no gamepad mapping API (glfwGetGamepadState);
+// the defaults fit an Xbox-style controller on Linux.
+type gamepadConfig struct {
+	enabled   bool
+	joystick  int     // 0 picks the first connected joystick, 1-16 a specific slot
+	deadzone  float32 // fraction of stick travel ignored around the center
+	lookSpeed float32 // degrees per second at full deflection
+	zoomSpeed float32 // zoom steps per second with a trigger fully pressed
+	invertY   bool
+	axes      [padAxisCount]int // -1 disables
+	buttons   map[int]inputAction
+}
+
+// defaultGamepadConfig maps the right stick to look, left stick to move, triggers to zoom,
+// Start to pause, Back to reset, shoulders to spin speed, Y to reverse, and B to camera mode.
+func defaultGamepadConfig() gamepadConfig {
+	return gamepadConfig{
+		enabled:   true,
+		deadzone:  0.15,
+		lookSpeed: 120,
+		zoomSpeed: 4,
+		axes:      [padAxisCount]int{3, 4, 0, 1, 5, 2},
+		buttons: map[int]inputAction{
+			7: actionPause,
+			6: actionCameraReset,
+			4: actionRotationSlower,
+			5: actionRotationFaster,
+			3: actionRotationReverse,
+			1: actionCameraMode,
+		},
+	}
+}
+
+// parseGamepadAxes applies `axes:` overrides by name, logging unknown names.
+func (c *gamepadConfig) parseGamepadAxes(axes map[string]int) {
+	for name, index := range axes {
+		found := false
+		for i, n := range padAxisNames {
+			if strings.EqualFold(n, strings.TrimSpace(name)) {
+				c.axes[i] = index
+				found = true
+			}
+		}
+		if !found {
+			log.Printf("config: gamepad.axes: unknown axis %q (want one of %s)", name, strings.Join(padAxisNames[:], ", "))
+		}
+	}
+}
+
+// parseGamepadButtons replaces the button map with button index -> action name entries.
+func (c *gamepadConfig) parseGamepadButtons(buttons map[int]string) {
+	c.buttons = map[int]inputAction{}
+	for index, name := range buttons {
+		a := lookupAction(name)
+		if a == actionNone {
+			log.Printf("config: gamepad.buttons.%d: unknown action %q", index, name)
+			continue
+		}
+		c.buttons[index] = a
+	}
+}
+
+// gamepad polls one joystick per frame and turns its state into camera motion and actions.
+type gamepad struct {
+	cfg       gamepadConfig
+	joy       glfw.Joystick
+	connected bool
+	buttons   []byte
+}
+
+// newGamepad returns nil when gamepad input is disabled.
+func newGamepad(cfg gamepadConfig) *gamepad {
+	if !cfg.enabled {
+		return nil
+	}
+	return &gamepad{cfg: cfg}
+}
+
+// findJoystick returns the configured slot, or the first connected one when joystick is 0.
+func (p *gamepad) findJoystick() (glfw.Joystick, bool) {
+	if p.cfg.joystick > 0 {
+		j := glfw.Joystick1 + glfw.Joystick(p.cfg.joystick-1)
+		return j, glfw.JoystickPresent(j)
+	}
+	for j := glfw.Joystick1; j <= glfw.JoystickLast; j++ {
+		if glfw.JoystickPresent(j) {
+			return j, true
+		}
+	}
+	return glfw.Joystick1, false
+}
+
+// poll reads the joystick and feeds it through liveInput for a frame of dt seconds, so
+// gamepad input is recorded like the keyboard and mouse.
+func (p *gamepad) poll(w *glfw.Window, app *VulkanApp, dt float32) {
+	joy, ok := p.findJoystick()
+	if !ok {
+		if p.connected {
+			log.Printf("Gamepad: disconnected")
+			p.release(w, app)
+			p.connected = false
+		}
+		return
+	}
+	if !p.connected || joy != p.joy {
+		log.Printf("Gamepad: using %q (joystick %d)", glfw.GetJoystickName(joy), int(joy-glfw.Joystick1)+1)
+		p.joy, p.connected, p.buttons = joy, true, nil
+	}
+	p.pollButtons(w, app, glfw.GetJoystickButtons(joy))
+	// Sticks act every frame they are held, so their state is sent every frame.
+	liveInput(w, app, inputEvent{Type: inputPadAxes, Axes: glfw.GetJoystickAxes(joy), DT: dt})
+}
+
+// pollButtons sends a pad_button event for every button that changed since the last poll.
+func (p *gamepad) pollButtons(w *glfw.Window, app *VulkanApp, buttons []byte) {
+	for i, state := range buttons {
+		was := i < len(p.buttons) && glfw.Action(p.buttons[i]) == glfw.Press
+		now := glfw.Action(state) == glfw.Press
+		if was != now {
+			liveInput(w, app, inputEvent{Type: inputPadButton, Button: i, Action: int(state)})
+		}
+	}
+	p.buttons = append(p.buttons[:0], buttons...)
+}
+
+// release lets go of any held buttons and centers the sticks when the controller
+// disappears mid-press.
+func (p *gamepad) release(w *glfw.Window, app *VulkanApp) {
+	for i, state := range p.buttons {
+		if glfw.Action(state) == glfw.Press {
+			liveInput(w, app, inputEvent{Type: inputPadButton, Button: i, Action: int(glfw.Release)})
+		}
+	}
+	p.buttons = nil
+	liveInput(w, app, inputEvent{Type: inputPadAxes})
+}
+
+// handlePadButton fires the button's mapped action on press, and on release for held actions.
+func handlePadButton(w *glfw.Window, app *VulkanApp, button int, pressed bool) {
+	a, mapped := app.cfg.gamepad.buttons[button]
+	if !mapped {
+		return
+	}
+	if pressed {
+		handleAction(w, app, a, true)
+	} else if actionTable[a].held {
+		handleAction(w, app, a, false)
+	}
+}
+
+// applyPadAxes turns sticks into orbit/pan or fly look/move, and triggers into zoom. Missing
+// axes read as centered, so an empty slice stops any stick motion.
+func applyPadAxes(cfg *gamepadConfig, rig *cameraRig, axes []float32, dt float32) {
+	axis := func(a gamepadAxis) float32 {
+		i := cfg.axes[a]
+		if i < 0 || i >= len(axes) {
+			return 0
+		}
+		return axes[i]
+	}
+	trigger := func(a gamepadAxis) float32 {
+		if i := cfg.axes[a]; i < 0 || i >= len(axes) {
+			return 0
+		}
+		return applyDeadzone1((axis(a)+1)/2, cfg.deadzone)
+	}
+	lookX, lookY := applyDeadzone(axis(padLookX), axis(padLookY), cfg.deadzone)
+	moveX, moveY := applyDeadzone(axis(padMoveX), axis(padMoveY), cfg.deadzone)
+	if cfg.invertY {
+		lookY = -lookY
+	}
+	zoom := trigger(padZoomIn) - trigger(padZoomOut)
+	look := cfg.lookSpeed * dt
+
+	if rig.mode == cameraFly {
+		rig.fly.look(-lookX*look, -lookY*look)
+		// Stick up is negative Y; triggers move vertically.
+		rig.fly.analog = mgl32.Vec3{moveX, -moveY, zoom}
+		return
+	}
+	rig.fly.analog = mgl32.Vec3{}
+	if lookX != 0 || lookY != 0 {
+		rig.orbit.orbit(lookX*look, -lookY*look)
+	}
+	if moveX != 0 || moveY != 0 {
+		const panPerSecond = 0.5 // view heights per second at full deflection
+		rig.orbit.pan(-moveX*panPerSecond*dt, -moveY*panPerSecond*dt)
+	}
+	if zoom != 0 {
+		rig.orbit.zoom(zoom * cfg.zoomSpeed * dt)
+	}
+}
+
+// applyDeadzone applies a radial deadzone to a stick and rescales the rest to [0, 1].
+func applyDeadzone(x, y, deadzone float32) (float32, float32) {
+	mag := float32(math.Hypot(float64(x), float64(y)))
+	if mag <= deadzone || mag == 0 {
+		return 0, 0
+	}
+	scaled := mgl32.Clamp((mag-deadzone)/(1-deadzone), 0, 1)
+	return x / mag * scaled, y / mag * scaled
+}
+
+// applyDeadzone1 applies a deadzone to a single [0, 1] trigger value.
+func applyDeadzone1(v, deadzone float32) float32 {
+	if v <= deadzone {
+		return 0
+	}
+	return mgl32.Clamp((v-deadzone)/(1-deadzone), 0, 1)
+}
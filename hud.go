@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+)
+
+// hudAnchor is the screen point a panel is laid out from.
+type hudAnchor int
+
+const (
+	anchorTopLeft hudAnchor = iota
+	anchorTopRight
+	anchorBottomLeft
+	anchorBottomRight
+	anchorCenter
+	anchorCount
+)
+
+var anchorNames = [anchorCount]string{"top_left", "top_right", "bottom_left", "bottom_right", "center"}
+
+// parseHUDAnchor accepts the names in anchorNames.
+func parseHUDAnchor(s string) (hudAnchor, error) {
+	for i, n := range anchorNames {
+		if strings.EqualFold(strings.TrimSpace(s), n) {
+			return hudAnchor(i), nil
+		}
+	}
+	return anchorTopLeft, fmt.Errorf("unknown anchor %q (want one of %s)", s, strings.Join(anchorNames[:], ", "))
+}
+
+// hudVerbosity is how much the HUD shows; each panel appears at its level and above.
+type hudVerbosity int
+
+const (
+	hudMinimal hudVerbosity = iota
+	hudNormal
+	hudVerbose
+	hudVerbosityCount
+)
+
+func (v hudVerbosity) String() string {
+	switch v {
+	case hudMinimal:
+		return "minimal"
+	case hudNormal:
+		return "normal"
+	}
+	return "verbose"
+}
+
+// parseHUDVerbosity accepts minimal, normal, or verbose.
+func parseHUDVerbosity(s string) (hudVerbosity, error) {
+	for v := hudMinimal; v < hudVerbosityCount; v++ {
+		if strings.EqualFold(strings.TrimSpace(s), v.String()) {
+			return v, nil
+		}
+	}
+	return hudNormal, fmt.Errorf("unknown HUD verbosity %q (want minimal, normal, or verbose)", s)
+}
+
+// hudPanel is a named block of text. update runs before every layout and returns the text,
+// which may span several lines; an empty result hides the panel unless it also has a graph.
+type hudPanel struct {
+	name      string
+	anchor    hudAnchor
+	color     mgl32.Vec3
+	scale     float32 // font pixel size in screen pixels, before the global HUD scale
+	verbosity hudVerbosity
+	update    func() string
+	graph     func() *hudGraph // optional bar chart drawn below the text
+}
+
+// hudGraph is a bar chart with one bar per value, oldest on the left.
+type hudGraph struct {
+	values []float32
+	top    float32 // value that reaches the top of the graph
+	ref    float32 // a reference line is drawn at this value when > 0
+	width  float32 // screen pixels before the global HUD scale
+	height float32
+}
+
+// hudText is a panel's text placed in screen pixels, ready to turn into glyph quads, plus
+// its graph's rectangle when it has one. w and h bound the text and graph together.
+type hudText struct {
+	text                           string
+	x, y, w, h                     float32
+	scale                          float32
+	color                          mgl32.Vec3
+	graph                          *hudGraph
+	graphX, graphY, graphW, graphH float32
+}
+
+// hudOverride is the `hud_panels:` config for one panel; nil fields keep the panel's own.
+type hudOverride struct {
+	anchor    *hudAnchor
+	color     *mgl32.Vec3
+	scale     *float32
+	verbosity *hudVerbosity
+}
+
+// hud holds the registered panels in draw order.
+type hud struct {
+	panels    []*hudPanel
+	verbosity hudVerbosity
+	overrides map[string]hudOverride
+}
+
+// newHUD starts at the given verbosity with per-panel config overrides.
+func newHUD(verbosity hudVerbosity, overrides map[string]hudOverride) *hud {
+	return &hud{verbosity: verbosity, overrides: overrides}
+}
+
+// hudMargin is the gap between panels and the screen edge, and between stacked panels, in
+// screen pixels before scaling.
+const hudMargin = 8
+
+// hudPanelPadding is how far a panel's background extends past its contents; it stays under
+// half the margin so stacked backgrounds don't overlap.
+const hudPanelPadding = 3
+
+// add registers p, replacing any panel with the same name in place. Config overrides for
+// the name win over what the caller asked for.
+func (h *hud) add(p hudPanel) {
+	if o, ok := h.overrides[p.name]; ok {
+		if o.anchor != nil {
+			p.anchor = *o.anchor
+		}
+		if o.color != nil {
+			p.color = *o.color
+		}
+		if o.scale != nil {
+			p.scale = *o.scale
+		}
+		if o.verbosity != nil {
+			p.verbosity = *o.verbosity
+		}
+	}
+	for i, old := range h.panels {
+		if old.name == p.name {
+			h.panels[i] = &p
+			return
+		}
+	}
+	h.panels = append(h.panels, &p)
+}
+
+// find returns the named panel, or nil.
+func (h *hud) find(name string) *hudPanel {
+	for _, p := range h.panels {
+		if p.name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// remove unregisters the named panel.
+func (h *hud) remove(name string) {
+	for i, p := range h.panels {
+		if p.name == name {
+			h.panels = append(h.panels[:i], h.panels[i+1:]...)
+			return
+		}
+	}
+}
+
+// cycleVerbosity steps minimal -> normal -> verbose -> minimal.
+func (h *hud) cycleVerbosity() hudVerbosity {
+	h.verbosity = (h.verbosity + 1) % hudVerbosityCount
+	return h.verbosity
+}
+
+// layout runs every visible panel's update and places the results on a width x height screen.
+// Panels sharing a corner stack away from it in registration order; centered panels stack
+// downward from the middle.
+func (h *hud) layout(width, height, scale float32) []hudText {
+	var out []hudText
+	var offset [anchorCount]float32
+	for _, p := range h.panels {
+		if p.verbosity > h.verbosity || (p.update == nil && p.graph == nil) {
+			continue
+		}
+		var text string
+		if p.update != nil {
+			text = strings.TrimRight(p.update(), "\n")
+		}
+		var graph *hudGraph
+		if p.graph != nil {
+			graph = p.graph()
+		}
+		if text == "" && graph == nil {
+			continue
+		}
+		s := p.scale * scale
+		margin := hudMargin * scale
+		var w, ht, textH float32
+		if text != "" {
+			cols, lines := textExtent(text)
+			w = float32(cols*fontAdvance-1) * s
+			textH = float32(lines*fontLineHeight-2) * s
+			ht = textH
+		}
+		var graphW, graphH float32
+		if graph != nil {
+			graphW, graphH = graph.width*scale, graph.height*scale
+			if text != "" {
+				ht += margin / 2
+			}
+			ht += graphH
+			if graphW > w {
+				w = graphW
+			}
+		}
+		var x, y float32
+		switch p.anchor {
+		case anchorTopLeft:
+			x, y = margin, margin+offset[p.anchor]
+		case anchorTopRight:
+			x, y = width-margin-w, margin+offset[p.anchor]
+		case anchorBottomLeft:
+			x, y = margin, height-margin-ht-offset[p.anchor]
+		case anchorBottomRight:
+			x, y = width-margin-w, height-margin-ht-offset[p.anchor]
+		case anchorCenter:
+			x, y = (width-w)/2, (height-ht)/2+offset[p.anchor]
+		}
+		offset[p.anchor] += ht + margin
+		t := hudText{text: text, x: x, y: y, w: w, h: ht, scale: s, color: p.color}
+		if graph != nil {
+			t.graph = graph
+			t.graphX, t.graphY, t.graphW, t.graphH = x, y+ht-graphH, graphW, graphH
+		}
+		out = append(out, t)
+	}
+	return out
+}
+
+// textExtent returns the widest line's length in character cells and the number of lines.
+func textExtent(text string) (cols, lines int) {
+	col := 0
+	lines = 1
+	for _, ch := range text {
+		switch ch {
+		case '\n':
+			col = 0
+			lines++
+			continue
+		case '\t':
+			col += fontTabStop - col%fontTabStop
+		default:
+			col++
+		}
+		if col > cols {
+			cols = col
+		}
+	}
+	return cols, lines
+}
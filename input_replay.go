@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// inputRecordVersion is bumped whenever the recording format changes incompatibly.
+const inputRecordVersion = 2
+
+// Input event types as written to recordings.
+const (
+	inputHeader = "header"
+	inputTick   = "tick"
+	inputKey    = "key"
+	inputButton = "button"
+	inputCursor = "cursor"
+	inputScroll = "scroll"
+	inputResize = "resize"
+
+	inputPadButton = "pad_button"
+	inputPadAxes   = "pad_axes"
+)
+
+// inputEvent is one line of a recording. Frame is the frame (one per clock tick) the event was
+// handled before; only the fields relevant to Type are set.
+type inputEvent struct {
+	Frame    uint64    `json:"frame"`
+	Type     string    `json:"type"`
+	Key      int       `json:"key,omitempty"`
+	Scancode int       `json:"scancode,omitempty"`
+	Button   int       `json:"button,omitempty"`
+	Action   int       `json:"action,omitempty"`
+	Mods     int       `json:"mods,omitempty"`
+	X        float64   `json:"x,omitempty"`
+	Y        float64   `json:"y,omitempty"`
+	Width    int       `json:"width,omitempty"` // window size in screen coordinates
+	Height   int       `json:"height,omitempty"`
+	Axes     []float32 `json:"axes,omitempty"` // raw joystick axes
+
+	// Tick: simulation steps run this frame, the clock remainder after them, and the
+	// wall-clock seconds the camera advanced. Pad axes: the seconds the sticks act for.
+	Steps   int     `json:"steps,omitempty"`
+	AccumNS int64   `json:"accum_ns,omitempty"`
+	DT      float32 `json:"dt,omitempty"`
+
+	// Header: what the session needs to line up with the recording.
+	Version   int     `json:"version,omitempty"`
+	StepHz    float64 `json:"step_hz,omitempty"`
+	TimeScale float64 `json:"time_scale,omitempty"`
+}
+
+// inputRecorder appends input events and per-frame clock ticks to a JSON Lines file.
+type inputRecorder struct {
+	f   *os.File
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+// newInputRecorder creates path and writes the header.
+func newInputRecorder(path string, header inputEvent) (*inputRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create input recording: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	r := &inputRecorder{f: f, w: w, enc: json.NewEncoder(w)}
+	header.Type = inputHeader
+	header.Version = inputRecordVersion
+	if err := r.write(header); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// write encodes one event.
+func (r *inputRecorder) write(ev inputEvent) error {
+	if err := r.enc.Encode(ev); err != nil {
+		return fmt.Errorf("write input recording: %w", err)
+	}
+	return nil
+}
+
+// flush pushes buffered events to disk so a crash loses at most the current frame.
+func (r *inputRecorder) flush() error {
+	return r.w.Flush()
+}
+
+// Close flushes and closes the file.
+func (r *inputRecorder) Close() error {
+	if err := r.w.Flush(); err != nil {
+		r.f.Close()
+		return fmt.Errorf("flush input recording: %w", err)
+	}
+	return r.f.Close()
+}
+
+// inputReplayer feeds a recording back one frame at a time.
+type inputReplayer struct {
+	header    inputEvent
+	events    []inputEvent
+	ticks     []inputEvent
+	pos       int
+	tickPos   int
+	lastFrame uint64
+}
+
+// loadInputReplay reads a whole recording and checks its header.
+func loadInputReplay(path string) (*inputReplayer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open input replay: %w", err)
+	}
+	defer f.Close()
+	r := &inputReplayer{}
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for line := 1; dec.More(); line++ {
+		var ev inputEvent
+		if err := dec.Decode(&ev); err != nil {
+			return nil, fmt.Errorf("input replay %s event %d: %w", path, line, err)
+		}
+		switch {
+		case line == 1:
+			if ev.Type != inputHeader || ev.Version != inputRecordVersion {
+				return nil, fmt.Errorf("input replay %s: not a version %d recording", path, inputRecordVersion)
+			}
+			// Same ranges loadAppConfig enforces for the clock block.
+			if !(ev.StepHz >= 1 && ev.StepHz <= 1000) {
+				return nil, fmt.Errorf("input replay %s: step_hz must be in [1, 1000] (got %g)", path, ev.StepHz)
+			}
+			if !(ev.TimeScale >= clockMinScale && ev.TimeScale <= clockMaxScale) {
+				return nil, fmt.Errorf("input replay %s: time_scale must be in [%g, %g] (got %g)", path, clockMinScale, clockMaxScale, ev.TimeScale)
+			}
+			r.header = ev
+		case ev.Type == inputTick:
+			if n := len(r.ticks); n > 0 && ev.Frame <= r.ticks[n-1].Frame {
+				return nil, fmt.Errorf("input replay %s event %d: tick for frame %d follows frame %d", path, line, ev.Frame, r.ticks[n-1].Frame)
+			}
+			r.ticks = append(r.ticks, ev)
+		default:
+			r.events = append(r.events, ev)
+		}
+		if ev.Frame > r.lastFrame {
+			r.lastFrame = ev.Frame
+		}
+	}
+	if r.header.Type == "" {
+		return nil, fmt.Errorf("input replay %s is empty", path)
+	}
+	return r, nil
+}
+
+// eventsFor returns the not-yet-replayed input events recorded up to and including frame.
+func (r *inputReplayer) eventsFor(frame uint64) []inputEvent {
+	start := r.pos
+	for r.pos < len(r.events) && r.events[r.pos].Frame <= frame {
+		r.pos++
+	}
+	return r.events[start:r.pos]
+}
+
+// tick returns the recorded clock advance for frame. Ticks are consumed in order, so each
+// one is replayed at most once.
+func (r *inputReplayer) tick(frame uint64) (steps int, accum time.Duration, dt float32, ok bool) {
+	for r.tickPos < len(r.ticks) && r.ticks[r.tickPos].Frame < frame {
+		r.tickPos++
+	}
+	if r.tickPos == len(r.ticks) || r.ticks[r.tickPos].Frame != frame {
+		return 0, 0, 0, false
+	}
+	ev := r.ticks[r.tickPos]
+	r.tickPos++
+	return ev.Steps, time.Duration(ev.AccumNS), ev.DT, true
+}
+
+// done reports whether every recorded frame has been replayed.
+func (r *inputReplayer) done(frame uint64) bool {
+	return r.pos == len(r.events) && frame > r.lastFrame
+}
@@ -0,0 +1,486 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vulkan-go/glfw/v3.3/glfw"
+	"gopkg.in/yaml.v3"
+)
+
+// inputAction is something a key can trigger, independent of which key that is.
+type inputAction int
+
+const (
+	actionNone inputAction = iota
+	actionQuit
+	actionPause
+	actionStep
+	actionTimeSlower
+	actionTimeFaster
+	actionTimeReset
+	actionScreenshot
+	actionToggleHUD
+	actionHUDVerbosity
+	actionToggleVsync
+	actionToggleFullscreen
+	actionNextMonitor
+	actionHelp
+	actionCameraReset
+	actionCameraMode
+	actionOrbitLeft
+	actionOrbitRight
+	actionOrbitUp
+	actionOrbitDown
+	actionPanLeft
+	actionPanRight
+	actionPanUp
+	actionPanDown
+	actionZoomIn
+	actionZoomOut
+	actionRotationSlower
+	actionRotationFaster
+	actionRotationReverse
+	actionRotationMode
+	actionFlyForward
+	actionFlyBack
+	actionFlyLeft
+	actionFlyRight
+	actionFlyDown
+	actionFlyUp
+	actionFlyBoost
+	actionCount
+)
+
+// actionInfo is the config name, help text, and default bindings of an action. Held actions
+// stay active until their key is released, so they keep working while other modifiers change;
+// repeat actions fire again on key auto-repeat.
+type actionInfo struct {
+	name     string
+	help     string
+	held     bool
+	repeat   bool
+	defaults []string
+}
+
+var actionTable = [actionCount]actionInfo{
+	actionQuit:             {name: "quit", help: "Quit", defaults: []string{"Escape"}},
+	actionPause:            {name: "pause", help: "Pause/resume", defaults: []string{"Space"}},
+	actionStep:             {name: "step", help: "Step one tick while paused", defaults: []string{"Period"}},
+	actionTimeSlower:       {name: "time_slower", help: "Halve time scale", defaults: []string{"Minus"}},
+	actionTimeFaster:       {name: "time_faster", help: "Double time scale", defaults: []string{"Equal"}},
+	actionTimeReset:        {name: "time_reset", help: "Real-time speed", defaults: []string{"0"}},
+	actionScreenshot:       {name: "screenshot", help: "Save screenshot", defaults: []string{"F12"}},
+	actionToggleHUD:        {name: "toggle_hud", help: "Show/hide HUD", defaults: []string{"H"}},
+	actionHUDVerbosity:     {name: "hud_verbosity", help: "Cycle HUD detail", defaults: []string{"F3"}},
+	actionToggleVsync:      {name: "toggle_vsync", help: "Toggle vsync", defaults: []string{"V"}},
+	actionToggleFullscreen: {name: "toggle_fullscreen", help: "Toggle fullscreen", defaults: []string{"F11"}},
+	actionNextMonitor:      {name: "next_monitor", help: "Fullscreen on next monitor", defaults: []string{"Shift+F11"}},
+	actionHelp:             {name: "help", help: "Show/hide this help", defaults: []string{"F1"}},
+	actionCameraReset:      {name: "camera_reset", help: "Reset camera", defaults: []string{"R"}},
+	actionCameraMode:       {name: "camera_mode", help: "Orbit/fly camera", defaults: []string{"F"}},
+	actionOrbitLeft:        {name: "orbit_left", help: "Orbit left", repeat: true, defaults: []string{"Left"}},
+	actionOrbitRight:       {name: "orbit_right", help: "Orbit right", repeat: true, defaults: []string{"Right"}},
+	actionOrbitUp:          {name: "orbit_up", help: "Orbit up", repeat: true, defaults: []string{"Up"}},
+	actionOrbitDown:        {name: "orbit_down", help: "Orbit down", repeat: true, defaults: []string{"Down"}},
+	actionPanLeft:          {name: "pan_left", help: "Pan left", repeat: true, defaults: []string{"Shift+Left"}},
+	actionPanRight:         {name: "pan_right", help: "Pan right", repeat: true, defaults: []string{"Shift+Right"}},
+	actionPanUp:            {name: "pan_up", help: "Pan up", repeat: true, defaults: []string{"Shift+Up"}},
+	actionPanDown:          {name: "pan_down", help: "Pan down", repeat: true, defaults: []string{"Shift+Down"}},
+	actionZoomIn:           {name: "zoom_in", help: "Zoom in", repeat: true, defaults: []string{"PageUp", "KPAdd"}},
+	actionZoomOut:          {name: "zoom_out", help: "Zoom out", repeat: true, defaults: []string{"PageDown", "KPSubtract"}},
+	actionRotationSlower:   {name: "rotation_slower", help: "Slower spin", repeat: true, defaults: []string{"LeftBracket"}},
+	actionRotationFaster:   {name: "rotation_faster", help: "Faster spin", repeat: true, defaults: []string{"RightBracket"}},
+	actionRotationReverse:  {name: "rotation_reverse", help: "Reverse spin", defaults: []string{"Backslash"}},
+	actionRotationMode:     {name: "rotation_mode", help: "Frame/time spin", defaults: []string{"M"}},
+	actionFlyForward:       {name: "fly_forward", help: "Fly forward", held: true, defaults: []string{"W"}},
+	actionFlyBack:          {name: "fly_back", help: "Fly back", held: true, defaults: []string{"S"}},
+	actionFlyLeft:          {name: "fly_left", help: "Fly left", held: true, defaults: []string{"A"}},
+	actionFlyRight:         {name: "fly_right", help: "Fly right", held: true, defaults: []string{"D"}},
+	actionFlyDown:          {name: "fly_down", help: "Fly down", held: true, defaults: []string{"Q"}},
+	actionFlyUp:            {name: "fly_up", help: "Fly up", held: true, defaults: []string{"E"}},
+	actionFlyBoost:         {name: "fly_boost", help: "Fly faster", held: true, defaults: []string{"LeftShift", "RightShift"}},
+}
+
+// bindingMods are the modifiers that distinguish bindings; lock keys are ignored.
+const bindingMods = glfw.ModShift | glfw.ModControl | glfw.ModAlt | glfw.ModSuper
+
+// keyChord is a key plus the exact modifiers that must be held with it.
+type keyChord struct {
+	key  glfw.Key
+	mods glfw.ModifierKey
+}
+
+// keyMap resolves key events to actions.
+type keyMap struct {
+	bindings [actionCount][]keyChord
+}
+
+// defaultKeyMap returns the built-in bindings.
+func defaultKeyMap() *keyMap {
+	km := &keyMap{}
+	for a := actionNone + 1; a < actionCount; a++ {
+		for _, s := range actionTable[a].defaults {
+			chord, err := parseKeyChord(s, false)
+			if err != nil {
+				panic(fmt.Sprintf("default binding %s for %s: %v", s, actionTable[a].name, err))
+			}
+			km.bindings[a] = append(km.bindings[a], chord)
+		}
+	}
+	return km
+}
+
+// applyOverrides replaces the bindings of each named action. A chord taken over by an
+// override is removed from any action still on its defaults; problems are logged and skipped.
+func (km *keyMap) applyOverrides(overrides map[string]stringList) {
+	overridden := map[inputAction]bool{}
+	for name, list := range overrides {
+		a := lookupAction(name)
+		if a == actionNone {
+			log.Printf("config: keybindings: unknown action %q", name)
+			continue
+		}
+		var chords []keyChord
+		for _, s := range list {
+			chord, err := parseKeyChord(s, true)
+			if err != nil {
+				log.Printf("config: keybindings.%s: %v", name, err)
+				continue
+			}
+			chords = append(chords, chord)
+		}
+		km.bindings[a] = chords
+		overridden[a] = true
+	}
+	for a := range overridden {
+		for _, chord := range km.bindings[a] {
+			for other := actionNone + 1; other < actionCount; other++ {
+				if other == a || !km.has(other, chord) {
+					continue
+				}
+				if overridden[other] {
+					if other < a {
+						continue // reported from the other side
+					}
+					log.Printf("config: keybindings: %s is bound to both %s and %s", chord, actionTable[a].name, actionTable[other].name)
+					continue
+				}
+				km.remove(other, chord)
+			}
+		}
+	}
+}
+
+// lookupAction finds an action by its config name, or returns actionNone.
+func lookupAction(name string) inputAction {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for a := actionNone + 1; a < actionCount; a++ {
+		if actionTable[a].name == name {
+			return a
+		}
+	}
+	return actionNone
+}
+
+// has reports whether action a is bound to chord.
+func (km *keyMap) has(a inputAction, chord keyChord) bool {
+	for _, c := range km.bindings[a] {
+		if c == chord {
+			return true
+		}
+	}
+	return false
+}
+
+// remove drops chord from action a.
+func (km *keyMap) remove(a inputAction, chord keyChord) {
+	kept := km.bindings[a][:0]
+	for _, c := range km.bindings[a] {
+		if c != chord {
+			kept = append(kept, c)
+		}
+	}
+	km.bindings[a] = kept
+}
+
+// lookup finds the action for a key press. An exact modifier match wins; held actions also
+// match with extra modifiers so e.g. fly movement keeps working while boosting.
+func (km *keyMap) lookup(key glfw.Key, mods glfw.ModifierKey) inputAction {
+	mods &= bindingMods
+	for a := actionNone + 1; a < actionCount; a++ {
+		if km.has(a, keyChord{key: key, mods: mods}) {
+			return a
+		}
+	}
+	for a := actionNone + 1; a < actionCount; a++ {
+		if actionTable[a].held && km.has(a, keyChord{key: key}) {
+			return a
+		}
+	}
+	return actionNone
+}
+
+// heldActions returns every held action bound to key, whatever its modifiers, so a release
+// always stops the movement it started.
+func (km *keyMap) heldActions(key glfw.Key) []inputAction {
+	var out []inputAction
+	for a := actionNone + 1; a < actionCount; a++ {
+		if !actionTable[a].held {
+			continue
+		}
+		for _, c := range km.bindings[a] {
+			if c.key == key {
+				out = append(out, a)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// helpLines lists every bound action as "KEYS: HELP" for the help overlay.
+func (km *keyMap) helpLines() []string {
+	var lines []string
+	for a := actionNone + 1; a < actionCount; a++ {
+		if len(km.bindings[a]) == 0 {
+			continue
+		}
+		keys := make([]string, len(km.bindings[a]))
+		for i, c := range km.bindings[a] {
+			keys[i] = c.label()
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", strings.Join(keys, " "), actionTable[a].help))
+	}
+	return lines
+}
+
+// String formats the chord the way the config accepts it, e.g. "Ctrl+Shift+S".
+func (c keyChord) String() string {
+	var parts []string
+	for _, m := range modifierNames {
+		if c.mods&m.mod != 0 {
+			parts = append(parts, m.name)
+		}
+	}
+	name := fmt.Sprintf("Key(%d)", int(c.key))
+	for _, k := range keyNames {
+		if k.key == c.key {
+			name = k.name
+			break
+		}
+	}
+	return strings.Join(append(parts, name), "+")
+}
+
+// label formats the chord for display, naming printable keys by what they type on the
+// active keyboard layout rather than their US position.
+func (c keyChord) label() string {
+	name := glfw.GetKeyName(c.key, 0)
+	if name == "" {
+		return c.String()
+	}
+	var parts []string
+	for _, m := range modifierNames {
+		if c.mods&m.mod != 0 {
+			parts = append(parts, m.name)
+		}
+	}
+	return strings.Join(append(parts, strings.ToUpper(name)), "+")
+}
+
+// parseKeyChord parses "Key" or "Mod+...+Key", case-insensitively. With byLayout set, a
+// single printable character names the key that types it on the active keyboard layout;
+// otherwise every name is a US key position.
+func parseKeyChord(s string, byLayout bool) (keyChord, error) {
+	parts := strings.Split(strings.TrimSpace(s), "+")
+	var chord keyChord
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if i < len(parts)-1 {
+			mod, ok := lookupModifier(p)
+			if !ok {
+				return keyChord{}, fmt.Errorf("unknown modifier %q in %q", p, s)
+			}
+			chord.mods |= mod
+			continue
+		}
+		key, ok := glfw.KeyUnknown, false
+		if byLayout {
+			key, ok = lookupLayoutKey(p)
+		}
+		if !ok {
+			key, ok = lookupKey(p)
+		}
+		if !ok {
+			return keyChord{}, fmt.Errorf("unknown key %q in %q", p, s)
+		}
+		chord.key = key
+	}
+	return chord, nil
+}
+
+var modifierNames = []struct {
+	name string
+	mod  glfw.ModifierKey
+}{
+	{"Ctrl", glfw.ModControl},
+	{"Alt", glfw.ModAlt},
+	{"Super", glfw.ModSuper},
+	{"Shift", glfw.ModShift},
+}
+
+// lookupModifier accepts the canonical names plus Control, Meta, and Cmd.
+func lookupModifier(name string) (glfw.ModifierKey, bool) {
+	switch strings.ToLower(name) {
+	case "control":
+		return glfw.ModControl, true
+	case "meta", "cmd":
+		return glfw.ModSuper, true
+	}
+	for _, m := range modifierNames {
+		if strings.EqualFold(m.name, name) {
+			return m.mod, true
+		}
+	}
+	return 0, false
+}
+
+// keyNames maps config names to GLFW keys. Printable keys are named for their position on a
+// US layout, which is how GLFW identifies them; lookupLayoutKey handles typed characters.
+var keyNames = func() []struct {
+	name string
+	key  glfw.Key
+} {
+	type entry = struct {
+		name string
+		key  glfw.Key
+	}
+	var out []entry
+	for c := 'A'; c <= 'Z'; c++ {
+		out = append(out, entry{string(c), glfw.KeyA + glfw.Key(c-'A')})
+	}
+	for c := '0'; c <= '9'; c++ {
+		out = append(out, entry{string(c), glfw.Key0 + glfw.Key(c-'0')})
+	}
+	for i := 1; i <= 12; i++ {
+		out = append(out, entry{fmt.Sprintf("F%d", i), glfw.KeyF1 + glfw.Key(i-1)})
+	}
+	for i := 0; i <= 9; i++ {
+		out = append(out, entry{fmt.Sprintf("KP%d", i), glfw.KeyKP0 + glfw.Key(i)})
+	}
+	return append(out,
+		entry{"Space", glfw.KeySpace},
+		entry{"Escape", glfw.KeyEscape},
+		entry{"Enter", glfw.KeyEnter},
+		entry{"Tab", glfw.KeyTab},
+		entry{"Backspace", glfw.KeyBackspace},
+		entry{"Insert", glfw.KeyInsert},
+		entry{"Delete", glfw.KeyDelete},
+		entry{"Left", glfw.KeyLeft},
+		entry{"Right", glfw.KeyRight},
+		entry{"Up", glfw.KeyUp},
+		entry{"Down", glfw.KeyDown},
+		entry{"PageUp", glfw.KeyPageUp},
+		entry{"PageDown", glfw.KeyPageDown},
+		entry{"Home", glfw.KeyHome},
+		entry{"End", glfw.KeyEnd},
+		entry{"PrintScreen", glfw.KeyPrintScreen},
+		entry{"Pause", glfw.KeyPause},
+		entry{"Apostrophe", glfw.KeyApostrophe},
+		entry{"Comma", glfw.KeyComma},
+		entry{"Minus", glfw.KeyMinus},
+		entry{"Period", glfw.KeyPeriod},
+		entry{"Slash", glfw.KeySlash},
+		entry{"Semicolon", glfw.KeySemicolon},
+		entry{"Equal", glfw.KeyEqual},
+		entry{"LeftBracket", glfw.KeyLeftBracket},
+		entry{"Backslash", glfw.KeyBackslash},
+		entry{"RightBracket", glfw.KeyRightBracket},
+		entry{"GraveAccent", glfw.KeyGraveAccent},
+		entry{"KPDecimal", glfw.KeyKPDecimal},
+		entry{"KPDivide", glfw.KeyKPDivide},
+		entry{"KPMultiply", glfw.KeyKPMultiply},
+		entry{"KPSubtract", glfw.KeyKPSubtract},
+		entry{"KPAdd", glfw.KeyKPAdd},
+		entry{"KPEnter", glfw.KeyKPEnter},
+		entry{"KPEqual", glfw.KeyKPEqual},
+		entry{"LeftShift", glfw.KeyLeftShift},
+		entry{"RightShift", glfw.KeyRightShift},
+		entry{"LeftControl", glfw.KeyLeftControl},
+		entry{"RightControl", glfw.KeyRightControl},
+		entry{"LeftAlt", glfw.KeyLeftAlt},
+		entry{"RightAlt", glfw.KeyRightAlt},
+	)
+}()
+
+// keySymbols lets printable keys be written as the character itself.
+var keySymbols = map[string]glfw.Key{
+	"'": glfw.KeyApostrophe, ",": glfw.KeyComma, "-": glfw.KeyMinus, ".": glfw.KeyPeriod,
+	"/": glfw.KeySlash, ";": glfw.KeySemicolon, "=": glfw.KeyEqual, "[": glfw.KeyLeftBracket,
+	"\\": glfw.KeyBackslash, "]": glfw.KeyRightBracket, "`": glfw.KeyGraveAccent,
+}
+
+// layoutKeys are the printable keys whose layout name GLFW can report.
+var layoutKeys = func() []glfw.Key {
+	var keys []glfw.Key
+	for k := glfw.KeyA; k <= glfw.KeyZ; k++ {
+		keys = append(keys, k)
+	}
+	for k := glfw.Key0; k <= glfw.Key9; k++ {
+		keys = append(keys, k)
+	}
+	for _, k := range keySymbols {
+		keys = append(keys, k)
+	}
+	return append(keys, glfw.KeyWorld1, glfw.KeyWorld2)
+}()
+
+// lookupLayoutKey finds the key that types the single character name on the active keyboard
+// layout, e.g. "Z" is the key labeled Z on AZERTY. GLFW must be initialized.
+func lookupLayoutKey(name string) (glfw.Key, bool) {
+	if utf8.RuneCountInString(name) != 1 {
+		return glfw.KeyUnknown, false
+	}
+	for _, k := range layoutKeys {
+		if strings.EqualFold(glfw.GetKeyName(k, 0), name) {
+			return k, true
+		}
+	}
+	return glfw.KeyUnknown, false
+}
+
+// lookupKey resolves a key name or symbol by its US position.
+func lookupKey(name string) (glfw.Key, bool) {
+	if k, ok := keySymbols[name]; ok {
+		return k, true
+	}
+	if strings.EqualFold(name, "Esc") {
+		return glfw.KeyEscape, true
+	}
+	for _, k := range keyNames {
+		if strings.EqualFold(k.name, name) {
+			return k.key, true
+		}
+	}
+	return glfw.KeyUnknown, false
+}
+
+// stringList accepts either a single YAML scalar or a sequence of strings.
+type stringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler.
+func (l *stringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = stringList{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+	"gopkg.in/yaml.v3"
+)
+
+// interpolation selects how a track blends between neighbouring keyframes.
+type interpolation int
+
+const (
+	interpLinear interpolation = iota
+	interpSlerp
+	interpCubic
+)
+
+// parseInterpolation accepts "linear", "slerp", and "cubic"; empty means linear.
+func parseInterpolation(s string) (interpolation, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "linear":
+		return interpLinear, nil
+	case "slerp":
+		return interpSlerp, nil
+	case "cubic":
+		return interpCubic, nil
+	}
+	return interpLinear, fmt.Errorf("unknown interpolation %q (want linear, slerp, or cubic)", s)
+}
+
+// animationFile is the YAML layout of a keyframe animation.
+type animationFile struct {
+	Loop     bool           `yaml:"loop"`
+	Duration *float32       `yaml:"duration"`
+	Tracks   animationTrack `yaml:"tracks"`
+}
+
+type animationTrack struct {
+	Rotation    *trackFile `yaml:"rotation"`
+	Translation *trackFile `yaml:"translation"`
+	Scale       *trackFile `yaml:"scale"`
+}
+
+type trackFile struct {
+	Interpolation string `yaml:"interpolation"`
+	Keys          []struct {
+		Time  float32   `yaml:"time"`
+		Value []float32 `yaml:"value"`
+	} `yaml:"keys"`
+}
+
+// keyframe is one sample of a track; rotations use all four components (x, y, z, w).
+type keyframe struct {
+	time  float32
+	value mgl32.Vec4
+}
+
+// keyTrack is a time-sorted list of keyframes with a blend mode.
+type keyTrack struct {
+	interp   interpolation
+	rotation bool // values are quaternions
+	keys     []keyframe
+}
+
+// keyframeAnimation drives the model matrix from rotation, translation, and scale tracks.
+type keyframeAnimation struct {
+	loop        bool
+	duration    float32
+	rotation    *keyTrack
+	translation *keyTrack
+	scale       *keyTrack
+}
+
+// loadKeyframeAnimation reads and validates a YAML keyframe animation.
+func loadKeyframeAnimation(path string) (*keyframeAnimation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read animation: %w", err)
+	}
+	var af animationFile
+	if err := yaml.Unmarshal(data, &af); err != nil {
+		return nil, fmt.Errorf("parse animation %s: %w", path, err)
+	}
+	anim := &keyframeAnimation{loop: af.Loop}
+	if anim.rotation, err = parseTrack("rotation", af.Tracks.Rotation, 4); err != nil {
+		return nil, err
+	}
+	if anim.translation, err = parseTrack("translation", af.Tracks.Translation, 3); err != nil {
+		return nil, err
+	}
+	if anim.scale, err = parseTrack("scale", af.Tracks.Scale, 3); err != nil {
+		return nil, err
+	}
+	if anim.rotation == nil && anim.translation == nil && anim.scale == nil {
+		return nil, fmt.Errorf("animation %s has no tracks", path)
+	}
+	for _, tr := range []*keyTrack{anim.rotation, anim.translation, anim.scale} {
+		if tr != nil && tr.keys[len(tr.keys)-1].time > anim.duration {
+			anim.duration = tr.keys[len(tr.keys)-1].time
+		}
+	}
+	if af.Duration != nil {
+		if *af.Duration <= 0 {
+			return nil, fmt.Errorf("animation duration must be > 0 (got %g)", *af.Duration)
+		}
+		anim.duration = *af.Duration
+	}
+	return anim, nil
+}
+
+// parseTrack converts a YAML track, checking value sizes and sorting keys by time.
+func parseTrack(name string, tf *trackFile, size int) (*keyTrack, error) {
+	if tf == nil {
+		return nil, nil
+	}
+	interp, err := parseInterpolation(tf.Interpolation)
+	if err != nil {
+		return nil, fmt.Errorf("%s track: %w", name, err)
+	}
+	if interp == interpSlerp && size != 4 {
+		return nil, fmt.Errorf("%s track: slerp only applies to rotation", name)
+	}
+	if len(tf.Keys) == 0 {
+		return nil, fmt.Errorf("%s track has no keys", name)
+	}
+	tr := &keyTrack{interp: interp, rotation: size == 4}
+	for i, k := range tf.Keys {
+		if len(k.Value) != size {
+			return nil, fmt.Errorf("%s key %d: want %d values, got %d", name, i, size, len(k.Value))
+		}
+		if k.Time < 0 {
+			return nil, fmt.Errorf("%s key %d: negative time %g", name, i, k.Time)
+		}
+		var v mgl32.Vec4
+		copy(v[:], k.Value)
+		if size == 4 {
+			q := mgl32.Quat{W: v[3], V: v.Vec3()}
+			if q.Len() == 0 {
+				return nil, fmt.Errorf("%s key %d: zero quaternion", name, i)
+			}
+			q = q.Normalize()
+			v = q.V.Vec4(q.W)
+		}
+		tr.keys = append(tr.keys, keyframe{time: k.Time, value: v})
+	}
+	sort.SliceStable(tr.keys, func(i, j int) bool { return tr.keys[i].time < tr.keys[j].time })
+	return tr, nil
+}
+
+// model evaluates all tracks at t seconds and composes translation * rotation * scale.
+func (a *keyframeAnimation) model(seconds float64) mgl32.Mat4 {
+	t := a.localTime(seconds)
+	m := mgl32.Ident4()
+	if a.translation != nil {
+		v := a.translation.sample(t)
+		m = mgl32.Translate3D(v[0], v[1], v[2])
+	}
+	if a.rotation != nil {
+		v := a.rotation.sample(t)
+		q := mgl32.Quat{W: v[3], V: v.Vec3()}.Normalize()
+		m = m.Mul4(q.Mat4())
+	}
+	if a.scale != nil {
+		v := a.scale.sample(t)
+		m = m.Mul4(mgl32.Scale3D(v[0], v[1], v[2]))
+	}
+	return m
+}
+
+// localTime wraps t into the animation when looping, otherwise holds the last pose. The wrap
+// is done in float64 so long runs keep their precision.
+func (a *keyframeAnimation) localTime(t float64) float32 {
+	if a.duration <= 0 {
+		return 0
+	}
+	if a.loop {
+		t = math.Mod(t, float64(a.duration))
+		if t < 0 {
+			t += float64(a.duration)
+		}
+		return float32(t)
+	}
+	return float32(math.Min(math.Max(t, 0), float64(a.duration)))
+}
+
+// sample blends the keys surrounding t, clamping outside the keyed range.
+func (tr *keyTrack) sample(t float32) mgl32.Vec4 {
+	keys := tr.keys
+	if t <= keys[0].time {
+		return keys[0].value
+	}
+	last := len(keys) - 1
+	if t >= keys[last].time {
+		return keys[last].value
+	}
+	i := sort.Search(len(keys), func(i int) bool { return keys[i].time > t }) - 1
+	k0, k1 := keys[i], keys[i+1]
+	u := (t - k0.time) / (k1.time - k0.time)
+	switch tr.interp {
+	case interpSlerp:
+		q0 := mgl32.Quat{W: k0.value[3], V: k0.value.Vec3()}
+		q1 := mgl32.Quat{W: k1.value[3], V: k1.value.Vec3()}
+		if q0.Dot(q1) < 0 {
+			q1 = q1.Scale(-1)
+		}
+		q := mgl32.QuatSlerp(q0, q1, u)
+		return q.V.Vec4(q.W)
+	case interpCubic:
+		p0, p3 := k0.value, k1.value
+		if i > 0 {
+			p0 = keys[i-1].value
+		}
+		if i+2 <= last {
+			p3 = keys[i+2].value
+		}
+		p1, p2 := k0.value, k1.value
+		if tr.rotation {
+			// Chain the control points onto one hemisphere so the spline takes the short way.
+			p0, p2 = alignQuat(p1, p0), alignQuat(p1, p2)
+			p3 = alignQuat(p2, p3)
+		}
+		return catmullRom(p0, p1, p2, p3, u)
+	}
+	v1 := k1.value
+	if tr.rotation {
+		v1 = alignQuat(k0.value, v1)
+	}
+	return k0.value.Add(v1.Sub(k0.value).Mul(u))
+}
+
+// alignQuat negates q if needed so it is on the same hemisphere as ref.
+func alignQuat(ref, q mgl32.Vec4) mgl32.Vec4 {
+	if ref.Dot(q) < 0 {
+		return q.Mul(-1)
+	}
+	return q
+}
+
+// catmullRom evaluates a uniform Catmull-Rom spline between p1 and p2.
+func catmullRom(p0, p1, p2, p3 mgl32.Vec4, u float32) mgl32.Vec4 {
+	u2 := u * u
+	u3 := u2 * u
+	return p1.Mul(2).
+		Add(p2.Sub(p0).Mul(u)).
+		Add(p0.Mul(2).Sub(p1.Mul(5)).Add(p2.Mul(4)).Sub(p3).Mul(u2)).
+		Add(p1.Mul(3).Sub(p0).Sub(p2.Mul(3)).Add(p3).Mul(u3)).
+		Mul(0.5)
+}
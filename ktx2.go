@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ktx2Identifier is the 12-byte magic every KTX2 file starts with.
+var ktx2Identifier = []byte{0xAB, 'K', 'T', 'X', ' ', '2', '0', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+const (
+	ktx2HeaderSize          = 80
+	ktx2LevelIndexEntrySize = 24
+
+	ktx2SupercompressionNone = 0
+	ktx2SupercompressionZlib = 3
+
+	// ktx2MaxLayers is the maxImageArrayLayers every Vulkan device guarantees; layers times
+	// faces must fit in it.
+	ktx2MaxLayers = 256
+	// ktx2MaxDimension bounds width and height well above any device's maxImageDimension2D,
+	// which keeps level size arithmetic from overflowing.
+	ktx2MaxDimension = 1 << 16
+)
+
+// ktx2Texture holds the decoded header and per-level payloads of a KTX2 container.
+// Each entry in levels covers every layer and face of that mip level, in file order, and is
+// still supercompressed; levelData inflates it once the expected size is known.
+type ktx2Texture struct {
+	vkFormat     uint32
+	width        uint32
+	height       uint32
+	layers       uint32
+	faces        uint32
+	scheme       uint32
+	levels       [][]byte
+	uncompressed []uint64
+}
+
+// loadKTX2File reads and parses a KTX2 file from disk.
+func loadKTX2File(path string) (*ktx2Texture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read ktx2: %w", err)
+	}
+	return parseKTX2(data)
+}
+
+// parseKTX2 validates the KTX2 header and slices out the mip level payloads.
+// Only 2D textures without supercompression (or with zlib) are supported.
+func parseKTX2(data []byte) (*ktx2Texture, error) {
+	if len(data) < ktx2HeaderSize || !bytes.Equal(data[:len(ktx2Identifier)], ktx2Identifier) {
+		return nil, fmt.Errorf("not a ktx2 file")
+	}
+	le := binary.LittleEndian
+	tex := &ktx2Texture{
+		vkFormat: le.Uint32(data[12:]),
+		width:    le.Uint32(data[20:]),
+		height:   le.Uint32(data[24:]),
+	}
+	depth := le.Uint32(data[28:])
+	layerCount := le.Uint32(data[32:])
+	faceCount := le.Uint32(data[36:])
+	levelCount := le.Uint32(data[40:])
+	scheme := le.Uint32(data[44:])
+
+	if tex.vkFormat == 0 {
+		return nil, fmt.Errorf("ktx2 with VK_FORMAT_UNDEFINED (Basis Universal) is not supported")
+	}
+	if tex.width == 0 || tex.height == 0 || tex.width > ktx2MaxDimension || tex.height > ktx2MaxDimension || depth > 1 {
+		return nil, fmt.Errorf("unsupported ktx2 dimensions %dx%dx%d", tex.width, tex.height, depth)
+	}
+	if faceCount != 1 && faceCount != 6 {
+		return nil, fmt.Errorf("invalid ktx2 face count %d", faceCount)
+	}
+	if scheme != ktx2SupercompressionNone && scheme != ktx2SupercompressionZlib {
+		return nil, fmt.Errorf("unsupported ktx2 supercompression scheme %d", scheme)
+	}
+	tex.faces = faceCount
+	tex.layers = layerCount
+	tex.scheme = scheme
+	if tex.layers == 0 {
+		tex.layers = 1
+	}
+	if uint64(tex.layers)*uint64(tex.faces) > ktx2MaxLayers {
+		return nil, fmt.Errorf("ktx2 has %d layers of %d faces; at most %d images are supported", tex.layers, tex.faces, ktx2MaxLayers)
+	}
+	if levelCount == 0 {
+		// levelCount 0 asks the loader to generate mips; we only use the base level.
+		levelCount = 1
+	}
+
+	if levelCount > 32 {
+		// A 2^16 texel edge has 17 mips; anything past 32 is a corrupt header.
+		return nil, fmt.Errorf("invalid ktx2 level count %d", levelCount)
+	}
+
+	indexEnd := ktx2HeaderSize + int(levelCount)*ktx2LevelIndexEntrySize
+	if len(data) < indexEnd {
+		return nil, fmt.Errorf("ktx2 level index truncated")
+	}
+	size := uint64(len(data))
+	tex.levels = make([][]byte, levelCount)
+	tex.uncompressed = make([]uint64, levelCount)
+	for i := range tex.levels {
+		entry := data[ktx2HeaderSize+i*ktx2LevelIndexEntrySize:]
+		offset := le.Uint64(entry[0:])
+		length := le.Uint64(entry[8:])
+		if offset > size || length > size-offset {
+			return nil, fmt.Errorf("ktx2 level %d out of range (offset %d length %d)", i, offset, length)
+		}
+		tex.levels[i] = data[offset : offset+length]
+		tex.uncompressed[i] = le.Uint64(entry[16:])
+	}
+	return tex, nil
+}
+
+// levelData returns mip level's payload, inflating it if supercompressed. want is the size
+// the level's format and dimensions call for; a header claiming more is rejected rather
+// than trusted with an allocation.
+func (t *ktx2Texture) levelData(level int, want int) ([]byte, error) {
+	if t.scheme != ktx2SupercompressionZlib {
+		return t.levels[level], nil
+	}
+	if t.uncompressed[level] > uint64(want) {
+		return nil, fmt.Errorf("ktx2 level %d claims %d uncompressed bytes, want %d", level, t.uncompressed[level], want)
+	}
+	data, err := inflateKTX2Level(t.levels[level], t.uncompressed[level])
+	if err != nil {
+		return nil, fmt.Errorf("ktx2 level %d: %w", level, err)
+	}
+	return data, nil
+}
+
+// inflateKTX2Level decompresses a zlib supercompressed level payload.
+func inflateKTX2Level(payload []byte, size uint64) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("zlib: %w", err)
+	}
+	defer r.Close()
+	out := make([]byte, size)
+	if _, err := io.ReadFull(r, out); err != nil {
+		return nil, fmt.Errorf("zlib: %w", err)
+	}
+	return out, nil
+}
+
+// levelSize returns the width and height of a mip level, clamped to one texel.
+func (t *ktx2Texture) levelSize(level int) (uint32, uint32) {
+	w := t.width >> uint(level)
+	h := t.height >> uint(level)
+	if w == 0 {
+		w = 1
+	}
+	if h == 0 {
+		h = 1
+	}
+	return w, h
+}
@@ -2,6 +2,8 @@ package main
 
 import (
 	"log"
+	"math"
+	"os"
 	"runtime"
 	"time"
 
@@ -20,13 +22,31 @@ func main() {
 	}
 	defer glfw.Terminate()
 
+	bench, benchOutput, err := parseArgs(os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg := loadAppConfig()
+	if bench {
+		cfg.prepareBench(benchOutput)
+	}
+	statePath, state := openSavedState(cfg.rememberState)
+
 	glfw.WindowHint(glfw.ClientAPI, glfw.NoAPI)
 	glfw.WindowHint(glfw.Resizable, glfw.True)
-	window, err := glfw.CreateWindow(800, 600, "Kube Vulkan (baseline window)", nil, nil)
+	// Keep a fullscreen window up when focus moves to another monitor (e.g. presenting).
+	glfw.WindowHint(glfw.AutoIconify, glfw.False)
+	window, err := glfw.CreateWindow(cfg.window.width, cfg.window.height, "Kube Vulkan (baseline window)", nil, nil)
 	if err != nil {
 		log.Fatalf("create window: %v", err)
 	}
 	defer window.Destroy()
+	display := newWindowState(cfg.window)
+	mode := cfg.window.mode
+	if state != nil {
+		mode = display.restore(window, state.Window)
+	}
+	display.apply(window, mode)
 
 	var app *VulkanApp
 	// Ensure the framebuffer has a non-zero size before initializing Vulkan.
@@ -39,39 +59,295 @@ func main() {
 	}
 
 	window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-		if key == glfw.KeyEscape && action == glfw.Press {
-			w.SetShouldClose(true)
-		}
-		if key == glfw.KeySpace && action == glfw.Press && app != nil {
-			app.togglePause()
+		if app == nil {
+			// Bindings take effect once the app exists; until then only Escape quits.
+			if key == glfw.KeyEscape && action == glfw.Press {
+				w.SetShouldClose(true)
+			}
+			return
 		}
+		liveInput(w, app, inputEvent{Type: inputKey, Key: int(key), Scancode: scancode, Action: int(action), Mods: int(mods)})
 	})
 
-	app, err = newVulkanApp(window)
+	app, err = newVulkanApp(window, cfg, display)
 	if err != nil {
 		log.Fatalf("init vulkan: %v", err)
 	}
 	defer app.Cleanup()
+	if state != nil {
+		app.restoreState(state)
+		if app.camera.mode == cameraFly {
+			setCameraMode(window, app.camera, cameraFly)
+		}
+	}
 
 	window.SetFramebufferSizeCallback(func(w *glfw.Window, width int, height int) {
 		app.requestSwapchainRecreate()
+		if app.replay == nil {
+			// Record screen coordinates, the unit SetSize takes on replay.
+			width, height := w.GetSize()
+			app.recordInput(inputEvent{Type: inputResize, Width: width, Height: height})
+		}
+	})
+	window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		x, y := w.GetCursorPos()
+		liveInput(w, app, inputEvent{Type: inputButton, Button: int(button), Action: int(action), Mods: int(mods), X: x, Y: y})
+	})
+	window.SetCursorPosCallback(func(w *glfw.Window, x, y float64) {
+		liveInput(w, app, inputEvent{Type: inputCursor, X: x, Y: y})
 	})
+	window.SetScrollCallback(func(w *glfw.Window, xoff, yoff float64) {
+		liveInput(w, app, inputEvent{Type: inputScroll, X: xoff, Y: yoff})
+	})
+
+	pad := newGamepad(app.cfg.gamepad)
+	lastPoll := time.Now()
 
 	log.Printf("Entering main loop")
 
 	for !window.ShouldClose() {
 		frameStart := time.Now()
 		glfw.PollEvents()
+		if app.replay != nil {
+			replayInput(window, app)
+		} else if pad != nil {
+			pad.poll(window, app, float32(frameStart.Sub(lastPoll).Seconds()))
+			lastPoll = frameStart
+		}
 		if err := app.DrawFrame(); err != nil {
 			log.Fatalf("draw frame: %v", err)
 		}
+		if app.bench != nil {
+			if app.bench.endFrame(time.Since(frameStart)) {
+				window.SetShouldClose(true)
+			}
+			app.writeFrameStats(frameStart, 0)
+			continue // run flat out
+		}
+		var sleep time.Duration
 		if app.cfg.maxFPS > 0 {
 			target := time.Second / time.Duration(app.cfg.maxFPS)
-			if sleep := target - time.Since(frameStart); sleep > 0 {
-				time.Sleep(sleep)
-			}
+			sleep = max(target-time.Since(frameStart), 0)
 		} else {
-			time.Sleep(1 * time.Millisecond) // small throttle to avoid busy loop
+			sleep = 1 * time.Millisecond // small throttle to avoid busy loop
+		}
+		if sleep > 0 {
+			time.Sleep(sleep)
 		}
+		app.writeFrameStats(frameStart, sleep)
+	}
+	if statePath != "" {
+		app.saveState(statePath)
+	}
+	if app.bench != nil {
+		app.finishBench()
+	}
+}
+
+// liveInput handles an event from the window, recording it when enabled. While a replay is
+// running live input is dropped so the session plays back exactly.
+func liveInput(w *glfw.Window, app *VulkanApp, ev inputEvent) {
+	if app.replay != nil {
+		return
+	}
+	app.recordInput(ev)
+	handleInput(w, app, ev)
+}
+
+// replayInput feeds the recorded events for the coming frame through handleInput.
+func replayInput(w *glfw.Window, app *VulkanApp) {
+	for _, ev := range app.replay.eventsFor(app.clock.frames) {
+		handleInput(w, app, ev)
+	}
+	if app.replay.done(app.clock.frames) {
+		app.endReplay()
+	}
+}
+
+// handleInput applies one key, mouse, scroll, gamepad, or resize event. Orbit mode: left drag orbits,
+// right/middle drag (or shift+left) pans, scroll zooms. Fly mode: the cursor is captured for
+// mouse-look and scroll changes speed.
+func handleInput(w *glfw.Window, app *VulkanApp, ev inputEvent) {
+	cam := app.camera
+	switch ev.Type {
+	case inputKey:
+		key, mods := glfw.Key(ev.Key), glfw.ModifierKey(ev.Mods)
+		keys := app.cfg.keys
+		switch glfw.Action(ev.Action) {
+		case glfw.Press:
+			handleAction(w, app, keys.lookup(key, mods), true)
+		case glfw.Repeat:
+			if a := keys.lookup(key, mods); actionTable[a].repeat {
+				handleAction(w, app, a, true)
+			}
+		case glfw.Release:
+			for _, a := range keys.heldActions(key) {
+				handleAction(w, app, a, false)
+			}
+		}
+	case inputButton:
+		button, mods := glfw.MouseButton(ev.Button), glfw.ModifierKey(ev.Mods)
+		if glfw.Action(ev.Action) == glfw.Release || cam.mode == cameraFly {
+			cam.orbit.endDrag()
+			return
+		}
+		switch {
+		case button == glfw.MouseButtonLeft && mods&glfw.ModShift == 0:
+			cam.orbit.beginDrag(dragOrbit, ev.X, ev.Y)
+		case button == glfw.MouseButtonLeft, button == glfw.MouseButtonRight, button == glfw.MouseButtonMiddle:
+			cam.orbit.beginDrag(dragPan, ev.X, ev.Y)
+		}
+	case inputCursor:
+		if cam.mode == cameraFly {
+			cam.fly.cursorMoved(ev.X, ev.Y)
+			return
+		}
+		_, height := w.GetSize()
+		cam.orbit.cursorMoved(ev.X, ev.Y, height)
+	case inputScroll:
+		if cam.mode == cameraFly {
+			cam.fly.scaleSpeed(float32(math.Pow(flySpeedStep, ev.Y)))
+			return
+		}
+		cam.orbit.zoom(float32(ev.Y))
+	case inputPadButton:
+		handlePadButton(w, app, ev.Button, glfw.Action(ev.Action) == glfw.Press)
+	case inputPadAxes:
+		applyPadAxes(&app.cfg.gamepad, cam, ev.Axes, ev.DT)
+	case inputResize:
+		// Live resizes already happened; a replay reproduces them on the window.
+		if app.replay != nil {
+			w.SetSize(ev.Width, ev.Height)
+		}
+	}
+}
+
+// handleAction performs a bound action. pressed is false only for the release of a held action.
+func handleAction(w *glfw.Window, app *VulkanApp, a inputAction, pressed bool) {
+	switch a {
+	case actionQuit:
+		w.SetShouldClose(true)
+	case actionPause:
+		app.togglePause()
+	case actionStep:
+		app.stepFrame()
+	case actionTimeSlower:
+		app.scaleTime(1 / clockScaleStep)
+	case actionTimeFaster:
+		app.scaleTime(clockScaleStep)
+	case actionTimeReset:
+		app.scaleTime(0)
+	case actionScreenshot:
+		app.requestScreenshot()
+	case actionToggleHUD:
+		app.toggleHUD()
+	case actionHUDVerbosity:
+		app.cycleHUDVerbosity()
+	case actionToggleVsync:
+		app.toggleVsync()
+	case actionToggleFullscreen:
+		app.toggleFullscreen()
+	case actionNextMonitor:
+		app.nextMonitor()
+	case actionHelp:
+		app.toggleHelp()
+	case actionCameraMode:
+		setCameraMode(w, app.camera, app.camera.toggleMode())
+	case actionRotationSlower, actionRotationFaster, actionRotationReverse, actionRotationMode:
+		handleRotationAction(app.rotation, a)
+	default:
+		handleCameraAction(app.camera, a, pressed)
+	}
+}
+
+// handleCameraAction applies camera actions: orbit/pan/zoom in orbit mode, held movement in
+// fly mode, and reset in either.
+func handleCameraAction(rig *cameraRig, a inputAction, pressed bool) {
+	const panStep = 0.05
+	if a == actionCameraReset {
+		rig.reset()
+		return
+	}
+	if actionTable[a].held {
+		// Releases always apply so a key let go after switching modes doesn't stick.
+		if rig.mode == cameraFly || !pressed {
+			setFlyMove(&rig.fly.move, a, pressed)
+		}
+		return
+	}
+	if rig.mode != cameraOrbit {
+		return
+	}
+	cam := rig.orbit
+	switch a {
+	case actionOrbitLeft:
+		cam.orbit(-cameraKeyOrbitDeg, 0)
+	case actionOrbitRight:
+		cam.orbit(cameraKeyOrbitDeg, 0)
+	case actionOrbitUp:
+		cam.orbit(0, cameraKeyOrbitDeg)
+	case actionOrbitDown:
+		cam.orbit(0, -cameraKeyOrbitDeg)
+	case actionPanLeft:
+		cam.pan(panStep, 0)
+	case actionPanRight:
+		cam.pan(-panStep, 0)
+	case actionPanUp:
+		cam.pan(0, panStep)
+	case actionPanDown:
+		cam.pan(0, -panStep)
+	case actionZoomIn:
+		cam.zoom(1)
+	case actionZoomOut:
+		cam.zoom(-1)
+	}
+}
+
+// setFlyMove records a held fly movement key.
+func setFlyMove(move *flyMove, a inputAction, held bool) {
+	switch a {
+	case actionFlyForward:
+		move.forward = held
+	case actionFlyBack:
+		move.back = held
+	case actionFlyLeft:
+		move.left = held
+	case actionFlyRight:
+		move.right = held
+	case actionFlyDown:
+		move.down = held
+	case actionFlyUp:
+		move.up = held
+	case actionFlyBoost:
+		move.boost = held
+	}
+}
+
+// handleRotationAction adjusts the model spin speed, direction, or animation mode.
+func handleRotationAction(rot *rotator, a inputAction) {
+	switch a {
+	case actionRotationSlower:
+		rot.scaleSpeed(1 / rotationSpeedStep)
+	case actionRotationFaster:
+		rot.scaleSpeed(rotationSpeedStep)
+	case actionRotationReverse:
+		rot.reverse()
+	case actionRotationMode:
+		rot.toggleMode()
+	}
+	log.Printf("Rotation: %s", rot.describe())
+}
+
+// flySpeedStep is the fly speed multiplier per scroll notch.
+const flySpeedStep = 1.25
+
+// setCameraMode captures the cursor for fly mode and releases it for orbit mode.
+func setCameraMode(w *glfw.Window, rig *cameraRig, mode cameraMode) {
+	if mode == cameraFly {
+		w.SetInputMode(glfw.CursorMode, glfw.CursorDisabled)
+		log.Printf("Camera: fly mode (speed %.2f)", rig.fly.speed)
+		return
 	}
+	w.SetInputMode(glfw.CursorMode, glfw.CursorNormal)
+	log.Printf("Camera: orbit mode")
 }
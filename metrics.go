@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// frameTimeBuckets are the upper bounds, in seconds, of the frame time histogram.
+var frameTimeBuckets = []float64{0.004, 0.008, 0.0167, 0.0333, 0.05, 0.1, 0.25, 0.5, 1}
+
+// validationSeverities are always exported, even at zero, so alerts have a series to watch.
+var validationSeverities = []string{"error", "warning", "performance_warning"}
+
+// memoryHeap is one Vulkan memory heap as reported by the device.
+type memoryHeap struct {
+	size        uint64
+	deviceLocal bool
+}
+
+// metrics holds the renderer's Prometheus counters and gauges. The render thread updates it
+// and the HTTP server reads it, so every access goes through mu. Methods on a nil *metrics do
+// nothing, so call sites don't need to check whether the endpoint is enabled.
+type metrics struct {
+	mu                 sync.Mutex
+	frames             uint64
+	swapchainRecreates uint64
+	validation         map[string]uint64
+	buckets            []uint64 // per frameTimeBuckets bound, not cumulative; the last is +Inf
+	frameTimeSum       float64
+	frameTimeCount     uint64
+	lastFrame          time.Time
+	gpuMemory          uint64
+	heaps              []memoryHeap
+}
+
+// newMetrics starts every counter at zero.
+func newMetrics() *metrics {
+	return &metrics{validation: make(map[string]uint64), buckets: make([]uint64, len(frameTimeBuckets)+1)}
+}
+
+// frameRendered counts a presented frame and adds its frame time to the histogram.
+func (m *metrics) frameRendered(frameTime time.Duration, now time.Time) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.frames++
+	m.lastFrame = now
+	if frameTime <= 0 {
+		return
+	}
+	s := frameTime.Seconds()
+	i := sort.SearchFloat64s(frameTimeBuckets, s)
+	m.buckets[i]++
+	m.frameTimeSum += s
+	m.frameTimeCount++
+}
+
+// swapchainRecreated counts a swapchain rebuild.
+func (m *metrics) swapchainRecreated() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.swapchainRecreates++
+	m.mu.Unlock()
+}
+
+// validationMessage counts a validation layer message of the given severity.
+func (m *metrics) validationMessage(severity string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.validation[severity]++
+	m.mu.Unlock()
+}
+
+// setGPUMemory records the device memory currently allocated by the renderer.
+func (m *metrics) setGPUMemory(bytes uint64) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.gpuMemory = bytes
+	m.mu.Unlock()
+}
+
+// setHeaps records the device's memory heaps.
+func (m *metrics) setHeaps(heaps []memoryHeap) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.heaps = heaps
+	m.mu.Unlock()
+}
+
+// writeTo renders every metric in the Prometheus text exposition format.
+func (m *metrics) writeTo(w io.Writer, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	p := &promWriter{w: w}
+	p.header("kube_frames_rendered_total", "counter", "Frames presented since startup.")
+	p.sample("kube_frames_rendered_total", "", float64(m.frames))
+	p.header("kube_swapchain_recreations_total", "counter", "Swapchain rebuilds (resize, out of date, vsync or fullscreen changes).")
+	p.sample("kube_swapchain_recreations_total", "", float64(m.swapchainRecreates))
+
+	p.header("kube_validation_messages_total", "counter", "Validation layer messages by severity.")
+	severities := append([]string(nil), validationSeverities...)
+	for s := range m.validation {
+		if !slices.Contains(severities, s) {
+			severities = append(severities, s)
+		}
+	}
+	sort.Strings(severities[len(validationSeverities):])
+	for _, s := range severities {
+		p.sample("kube_validation_messages_total", fmt.Sprintf(`severity=%q`, s), float64(m.validation[s]))
+	}
+
+	p.header("kube_frame_time_seconds", "histogram", "Time between presented frames.")
+	var cumulative uint64
+	for i, bound := range frameTimeBuckets {
+		cumulative += m.buckets[i]
+		p.sample("kube_frame_time_seconds_bucket", fmt.Sprintf(`le="%g"`, bound), float64(cumulative))
+	}
+	p.sample("kube_frame_time_seconds_bucket", `le="+Inf"`, float64(m.frameTimeCount))
+	p.sample("kube_frame_time_seconds_sum", "", m.frameTimeSum)
+	p.sample("kube_frame_time_seconds_count", "", float64(m.frameTimeCount))
+
+	if !m.lastFrame.IsZero() {
+		p.header("kube_last_frame_timestamp_seconds", "gauge", "Unix time the last frame was presented.")
+		p.sample("kube_last_frame_timestamp_seconds", "", float64(m.lastFrame.UnixNano())/1e9)
+		p.header("kube_seconds_since_last_frame", "gauge", "Seconds since the last frame was presented; grows while the renderer is stalled.")
+		p.sample("kube_seconds_since_last_frame", "", now.Sub(m.lastFrame).Seconds())
+	}
+
+	p.header("kube_gpu_memory_allocated_bytes", "gauge", "Device memory currently allocated by the renderer.")
+	p.sample("kube_gpu_memory_allocated_bytes", "", float64(m.gpuMemory))
+	if len(m.heaps) > 0 {
+		p.header("kube_gpu_memory_heap_size_bytes", "gauge", "Size of each device memory heap.")
+		for i, h := range m.heaps {
+			p.sample("kube_gpu_memory_heap_size_bytes", fmt.Sprintf(`heap="%d",device_local="%t"`, i, h.deviceLocal), float64(h.size))
+		}
+	}
+	return p.err
+}
+
+// promWriter writes exposition lines, keeping the first error.
+type promWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (p *promWriter) header(name, kind, help string) {
+	p.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func (p *promWriter) sample(name, labels string, value float64) {
+	if labels != "" {
+		name += "{" + labels + "}"
+	}
+	p.printf("%s %g\n", name, value)
+}
+
+func (p *promWriter) printf(format string, args ...any) {
+	if p.err == nil {
+		_, p.err = fmt.Fprintf(p.w, format, args...)
+	}
+}
+
+// metricsListenAddr fills in a missing host with 127.0.0.1 and rejects anything that isn't a
+// loopback address; the endpoint is meant for a local scraper, not the network.
+func metricsListenAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("metrics address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		return "", fmt.Errorf("metrics address %q is not a loopback address", addr)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+// serveMetrics listens on addr and serves m at /metrics in the background.
+func serveMetrics(addr string, m *metrics) (*http.Server, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics listen: %w", err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := m.writeTo(w, time.Now()); err != nil {
+			log.Printf("metrics: %v", err)
+		}
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("metrics: %v", err)
+		}
+	}()
+	return srv, nil
+}
+
+// stopMetricsServer shuts the endpoint down, giving in-flight scrapes a moment to finish.
+func stopMetricsServer(srv *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("metrics: %v", err)
+	}
+}
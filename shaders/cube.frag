@@ -1,9 +1,15 @@
 #version 450
 layout(location = 0) in vec3 fragColor;
 layout(location = 1) in vec2 fragUV;
+layout(location = 2) flat in uint fragFace;
 layout(location = 0) out vec4 outColor;
-layout(binding = 1) uniform sampler2D texSampler;
+layout(set = 1, binding = 0) uniform sampler2DArray texSampler;
+layout(push_constant) uniform DrawPush {
+    mat4 model;
+    vec4 baseColor;
+} draw;
 void main() {
-    vec4 tex = texture(texSampler, fragUV);
-    outColor = tex;
+    // Single-layer textures clamp every face to layer 0.
+    vec4 tex = texture(texSampler, vec3(fragUV, float(fragFace)));
+    outColor = tex * draw.baseColor;
 }
@@ -2,15 +2,22 @@
 layout(location = 0) in vec3 inPos;
 layout(location = 1) in vec3 inColor;
 layout(location = 2) in vec2 inUV;
-layout(binding = 0) uniform UniformBuffer {
+layout(location = 3) in uint inFace;
+layout(set = 0, binding = 0) uniform UniformBuffer {
     mat4 model;
     mat4 view;
     mat4 proj;
 } ubo;
+layout(push_constant) uniform DrawPush {
+    mat4 model;
+    vec4 baseColor;
+} draw;
 layout(location = 0) out vec3 fragColor;
 layout(location = 1) out vec2 fragUV;
+layout(location = 2) flat out uint fragFace;
 void main() {
     fragColor = inColor;
     fragUV = inUV;
-    gl_Position = ubo.proj * ubo.view * ubo.model * vec4(inPos, 1.0);
+    fragFace = inFace;
+    gl_Position = ubo.proj * ubo.view * ubo.model * draw.model * vec4(inPos, 1.0);
 }
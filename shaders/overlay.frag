@@ -1,6 +1,10 @@
 #version 450
-layout(location = 0) in vec3 fragColor;
+layout(set = 0, binding = 0) uniform sampler2D glyphAtlas;
+layout(location = 0) in vec4 fragColor;
+layout(location = 1) in vec2 fragUV;
 layout(location = 0) out vec4 outColor;
 void main() {
-    outColor = vec4(fragColor, 1.0);
+    // The atlas stores coverage in red; solid quads sample its fully lit cell, so their
+    // alpha is the vertex alpha alone.
+    outColor = vec4(fragColor.rgb, fragColor.a * texture(glyphAtlas, fragUV).r);
 }
@@ -1,8 +1,11 @@
 #version 450
 layout(location = 0) in vec2 inPos;
-layout(location = 1) in vec3 inColor;
-layout(location = 0) out vec3 fragColor;
+layout(location = 1) in vec2 inUV;
+layout(location = 2) in vec4 inColor;
+layout(location = 0) out vec4 fragColor;
+layout(location = 1) out vec2 fragUV;
 void main() {
     fragColor = inColor;
+    fragUV = inUV;
     gl_Position = vec4(inPos, 0.0, 1.0);
 }
@@ -0,0 +1,7 @@
+#version 450
+layout(location = 0) in vec3 fragDir;
+layout(location = 0) out vec4 outColor;
+layout(binding = 0) uniform samplerCube skybox;
+void main() {
+    outColor = texture(skybox, fragDir);
+}
@@ -0,0 +1,13 @@
+#version 450
+layout(location = 0) in vec3 inPos;
+layout(push_constant) uniform SkyboxPush {
+    mat4 viewProj;
+} pc;
+layout(location = 0) out vec3 fragDir;
+void main() {
+    // The scene is Z-up while cube maps are Y-up.
+    fragDir = vec3(inPos.x, inPos.z, -inPos.y);
+    vec4 clip = pc.viewProj * vec4(inPos, 1.0);
+    // Pin the skybox to the far plane so anything drawn earlier stays in front.
+    gl_Position = clip.xyww;
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// frameRecord is one main-loop iteration in the stats export. Times are nanoseconds since
+// the export started; a stage the frame never reached (e.g. acquire found the swapchain out
+// of date) is 0.
+type frameRecord struct {
+	Frame      uint64 `json:"frame"`
+	Presented  bool   `json:"presented"`
+	StartNS    int64  `json:"start_ns"`
+	AcquireNS  int64  `json:"acquire_ns"`
+	RecordNS   int64  `json:"record_ns"`
+	SubmitNS   int64  `json:"submit_ns"`
+	PresentNS  int64  `json:"present_ns"`
+	EndNS      int64  `json:"end_ns"` // after the frame limiter's sleep
+	ImageIndex int    `json:"image_index"`
+	SleepNS    int64  `json:"sleep_ns"`  // time the max_fps limiter slept
+	GPUNS      int64  `json:"gpu_ns"`    // GPU time of the most recently retired frame, 0 if unknown
+	Recreated  bool   `json:"recreated"` // the swapchain was rebuilt during this frame
+	Recreates  uint64 `json:"recreates"` // swapchain rebuilds so far
+}
+
+// frameRecordColumns is the CSV header, in frameRecord field order.
+var frameRecordColumns = []string{
+	"frame", "presented", "start_ns", "acquire_ns", "record_ns", "submit_ns", "present_ns",
+	"end_ns", "image_index", "sleep_ns", "gpu_ns", "recreated", "recreates",
+}
+
+// csvRow formats the record under frameRecordColumns.
+func (r frameRecord) csvRow() []string {
+	i := func(v int64) string { return strconv.FormatInt(v, 10) }
+	return []string{
+		strconv.FormatUint(r.Frame, 10), strconv.FormatBool(r.Presented),
+		i(r.StartNS), i(r.AcquireNS), i(r.RecordNS), i(r.SubmitNS), i(r.PresentNS), i(r.EndNS),
+		strconv.Itoa(r.ImageIndex), i(r.SleepNS), i(r.GPUNS),
+		strconv.FormatBool(r.Recreated), strconv.FormatUint(r.Recreates, 10),
+	}
+}
+
+// frameStatsFlushEvery bounds how many records a crash can lose without flushing every frame.
+const frameStatsFlushEvery = 60
+
+// frameStatsWriter streams frame records as CSV (for a .csv path) or JSON Lines.
+type frameStatsWriter struct {
+	f       *os.File
+	w       *bufio.Writer
+	csv     *csv.Writer
+	enc     *json.Encoder
+	start   time.Time
+	pending int
+}
+
+// newFrameStatsWriter creates path; records are timed from start.
+func newFrameStatsWriter(path string, start time.Time) (*frameStatsWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create frame stats: %w", err)
+	}
+	w := bufio.NewWriter(f)
+	s := &frameStatsWriter{f: f, w: w, start: start}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		s.csv = csv.NewWriter(w)
+		if err := s.csv.Write(frameRecordColumns); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("write frame stats: %w", err)
+		}
+	} else {
+		s.enc = json.NewEncoder(w)
+	}
+	return s, nil
+}
+
+// since converts t to nanoseconds after the export start; the zero time stays 0.
+func (s *frameStatsWriter) since(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return int64(t.Sub(s.start))
+}
+
+// write appends one record.
+func (s *frameStatsWriter) write(r frameRecord) error {
+	if s.csv != nil {
+		s.csv.Write(r.csvRow())
+		s.csv.Flush()
+		if err := s.csv.Error(); err != nil {
+			return fmt.Errorf("write frame stats: %w", err)
+		}
+	} else if err := s.enc.Encode(r); err != nil {
+		return fmt.Errorf("write frame stats: %w", err)
+	}
+	s.pending++
+	if s.pending >= frameStatsFlushEvery {
+		s.pending = 0
+		if err := s.w.Flush(); err != nil {
+			return fmt.Errorf("write frame stats: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close flushes and closes the file.
+func (s *frameStatsWriter) Close() error {
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return fmt.Errorf("flush frame stats: %w", err)
+	}
+	return s.f.Close()
+}
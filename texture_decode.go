@@ -0,0 +1,420 @@
+package main
+
+import "fmt"
+
+// Block-compressed texture decoders used when the device cannot sample a KTX2 format natively.
+// Each decoder expands 4x4 blocks into tightly packed RGBA8 pixels.
+
+// decodeBC1 expands BC1 (DXT1) blocks; punchThrough makes the three-color mode's black texel transparent.
+func decodeBC1(data []byte, width, height uint32, punchThrough bool) ([]byte, error) {
+	return decodeBlocks(data, width, height, 8, func(block []byte, out *[16][4]byte) {
+		decodeBC1Color(block, out, true, punchThrough)
+	})
+}
+
+// decodeBC3 expands BC3 (DXT5) blocks: an interpolated alpha block followed by a BC1 color block.
+func decodeBC3(data []byte, width, height uint32) ([]byte, error) {
+	return decodeBlocks(data, width, height, 16, func(block []byte, out *[16][4]byte) {
+		decodeBC1Color(block[8:], out, false, false)
+		decodeBC3Alpha(block[:8], out)
+	})
+}
+
+// decodeBC7 expands BC7 blocks covering all eight block modes.
+func decodeBC7(data []byte, width, height uint32) ([]byte, error) {
+	return decodeBlocks(data, width, height, 16, decodeBC7Block)
+}
+
+// decodeBlocks walks the 4x4 block grid and scatters decoded texels into an RGBA8 image.
+func decodeBlocks(data []byte, width, height uint32, blockSize int, decode func([]byte, *[16][4]byte)) ([]byte, error) {
+	blocksX := int(width+3) / 4
+	blocksY := int(height+3) / 4
+	if want := blocksX * blocksY * blockSize; len(data) < want {
+		return nil, fmt.Errorf("compressed data truncated: got %d want %d", len(data), want)
+	}
+	w, h := int(width), int(height)
+	out := make([]byte, w*h*4)
+	var texels [16][4]byte
+	for by := 0; by < blocksY; by++ {
+		for bx := 0; bx < blocksX; bx++ {
+			off := (by*blocksX + bx) * blockSize
+			decode(data[off:off+blockSize], &texels)
+			for i, t := range texels {
+				x := bx*4 + i%4
+				y := by*4 + i/4
+				if x >= w || y >= h {
+					continue
+				}
+				copy(out[(y*w+x)*4:], t[:])
+			}
+		}
+	}
+	return out, nil
+}
+
+// decodeBC1Color decodes the 565 endpoint color block shared by BC1 and BC3.
+// BC3 color blocks always use four colors; BC1 switches to three colors plus black when c0 <= c1.
+func decodeBC1Color(block []byte, out *[16][4]byte, threeColorMode, punchThrough bool) {
+	c0 := uint16(block[0]) | uint16(block[1])<<8
+	c1 := uint16(block[2]) | uint16(block[3])<<8
+	var palette [4][4]byte
+	palette[0] = expand565(c0)
+	palette[1] = expand565(c1)
+	if c0 > c1 || !threeColorMode {
+		for ch := 0; ch < 3; ch++ {
+			palette[2][ch] = byte((2*int(palette[0][ch]) + int(palette[1][ch]) + 1) / 3)
+			palette[3][ch] = byte((int(palette[0][ch]) + 2*int(palette[1][ch]) + 1) / 3)
+		}
+		palette[2][3], palette[3][3] = 255, 255
+	} else {
+		for ch := 0; ch < 3; ch++ {
+			palette[2][ch] = byte((int(palette[0][ch]) + int(palette[1][ch])) / 2)
+		}
+		palette[2][3] = 255
+		palette[3] = [4]byte{0, 0, 0, 255}
+		if punchThrough {
+			palette[3][3] = 0
+		}
+	}
+	indices := uint32(block[4]) | uint32(block[5])<<8 | uint32(block[6])<<16 | uint32(block[7])<<24
+	for i := range out {
+		out[i] = palette[(indices>>(2*uint(i)))&3]
+	}
+}
+
+// decodeBC3Alpha decodes the 8-bit interpolated alpha block of BC3 into the alpha channel.
+func decodeBC3Alpha(block []byte, out *[16][4]byte) {
+	var alpha [8]int
+	alpha[0], alpha[1] = int(block[0]), int(block[1])
+	if alpha[0] > alpha[1] {
+		for i := 1; i < 7; i++ {
+			alpha[i+1] = ((7-i)*alpha[0] + i*alpha[1] + 3) / 7
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			alpha[i+1] = ((5-i)*alpha[0] + i*alpha[1] + 2) / 5
+		}
+		alpha[6], alpha[7] = 0, 255
+	}
+	var bits uint64
+	for i := 0; i < 6; i++ {
+		bits |= uint64(block[2+i]) << (8 * uint(i))
+	}
+	for i := range out {
+		out[i][3] = byte(alpha[(bits>>(3*uint(i)))&7])
+	}
+}
+
+// expand565 widens an RGB565 color to RGBA8 with opaque alpha.
+func expand565(c uint16) [4]byte {
+	r := byte(c >> 11 & 0x1f)
+	g := byte(c >> 5 & 0x3f)
+	b := byte(c & 0x1f)
+	return [4]byte{r<<3 | r>>2, g<<2 | g>>4, b<<3 | b>>2, 255}
+}
+
+// bc7Mode describes the bit layout of one BC7 block mode.
+type bc7Mode struct {
+	subsets       int
+	partitionBits int
+	rotationBits  int
+	indexSelBits  int
+	colorBits     int
+	alphaBits     int
+	endpointPBits bool
+	sharedPBits   bool
+	indexBits     int
+	indexBits2    int
+}
+
+var bc7Modes = [8]bc7Mode{
+	{subsets: 3, partitionBits: 4, colorBits: 4, endpointPBits: true, indexBits: 3},
+	{subsets: 2, partitionBits: 6, colorBits: 6, sharedPBits: true, indexBits: 3},
+	{subsets: 3, partitionBits: 6, colorBits: 5, indexBits: 2},
+	{subsets: 2, partitionBits: 6, colorBits: 7, endpointPBits: true, indexBits: 2},
+	{subsets: 1, rotationBits: 2, indexSelBits: 1, colorBits: 5, alphaBits: 6, indexBits: 2, indexBits2: 3},
+	{subsets: 1, rotationBits: 2, colorBits: 7, alphaBits: 8, indexBits: 2, indexBits2: 2},
+	{subsets: 1, colorBits: 7, alphaBits: 7, endpointPBits: true, indexBits: 4},
+	{subsets: 2, partitionBits: 6, colorBits: 5, alphaBits: 5, endpointPBits: true, indexBits: 2},
+}
+
+var bc7Weights = [5][]int{
+	2: {0, 21, 43, 64},
+	3: {0, 9, 18, 27, 37, 46, 55, 64},
+	4: {0, 4, 9, 13, 17, 21, 26, 30, 34, 38, 43, 47, 51, 55, 60, 64},
+}
+
+// bc7Partitions2 stores the two-subset partition shapes as bitmasks (bit i set = texel i in subset 1).
+var bc7Partitions2 = [64]uint16{
+	0xcccc, 0x8888, 0xeeee, 0xecc8, 0xc880, 0xfeec, 0xfec8, 0xec80,
+	0xc800, 0xffec, 0xfe80, 0xe800, 0xffe8, 0xff00, 0xfff0, 0xf000,
+	0xf710, 0x008e, 0x7100, 0x08ce, 0x008c, 0x7310, 0x3100, 0x8cce,
+	0x088c, 0x3110, 0x6666, 0x366c, 0x17e8, 0x0ff0, 0x718e, 0x399c,
+	0xaaaa, 0xf0f0, 0x5a5a, 0x33cc, 0x3c3c, 0x55aa, 0x9696, 0xa55a,
+	0x73ce, 0x13c8, 0x324c, 0x3bdc, 0x6996, 0xc33c, 0x9966, 0x0660,
+	0x0272, 0x04e4, 0x4e40, 0x2720, 0xc936, 0x936c, 0x39c6, 0x639c,
+	0x9336, 0x9cc6, 0x817e, 0xe718, 0xccf0, 0x0fcc, 0x7744, 0xee22,
+}
+
+// bc7Partitions3 stores the three-subset partition shapes, one subset index per texel.
+var bc7Partitions3 = [64][16]uint8{
+	{0, 0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 1, 2, 2, 2, 2},
+	{0, 0, 0, 1, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 2, 0, 0, 1, 2, 2, 1, 1, 2, 2, 1, 1},
+	{0, 2, 2, 2, 0, 0, 2, 2, 0, 0, 1, 1, 0, 1, 1, 1},
+	{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2},
+	{0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 2, 2, 0, 0, 2, 2},
+	{0, 0, 2, 2, 0, 0, 2, 2, 1, 1, 1, 1, 1, 1, 1, 1},
+	{0, 0, 1, 1, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1},
+	{0, 0, 0, 0, 0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2},
+	{0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1, 2, 2, 2, 2},
+	{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2},
+	{0, 1, 1, 2, 0, 1, 1, 2, 0, 1, 1, 2, 0, 1, 1, 2},
+	{0, 1, 2, 2, 0, 1, 2, 2, 0, 1, 2, 2, 0, 1, 2, 2},
+	{0, 0, 1, 1, 0, 1, 1, 2, 1, 1, 2, 2, 1, 2, 2, 2},
+	{0, 0, 1, 1, 2, 0, 0, 1, 2, 2, 0, 0, 2, 2, 2, 0},
+	{0, 0, 0, 1, 0, 0, 1, 1, 0, 1, 1, 2, 1, 1, 2, 2},
+	{0, 1, 1, 1, 0, 0, 1, 1, 2, 0, 0, 1, 2, 2, 0, 0},
+	{0, 0, 0, 0, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 2, 2},
+	{0, 0, 2, 2, 0, 0, 2, 2, 0, 0, 2, 2, 1, 1, 1, 1},
+	{0, 1, 1, 1, 0, 1, 1, 1, 0, 2, 2, 2, 0, 2, 2, 2},
+	{0, 0, 0, 1, 0, 0, 0, 1, 2, 2, 2, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 0, 0, 1, 1, 0, 1, 2, 2, 0, 1, 2, 2},
+	{0, 0, 0, 0, 1, 1, 0, 0, 2, 2, 1, 0, 2, 2, 1, 0},
+	{0, 1, 2, 2, 0, 1, 2, 2, 0, 0, 1, 1, 0, 0, 0, 0},
+	{0, 0, 1, 2, 0, 0, 1, 2, 1, 1, 2, 2, 2, 2, 2, 2},
+	{0, 1, 1, 0, 1, 2, 2, 1, 1, 2, 2, 1, 0, 1, 1, 0},
+	{0, 0, 0, 0, 0, 1, 1, 0, 1, 2, 2, 1, 1, 2, 2, 1},
+	{0, 0, 2, 2, 1, 1, 0, 2, 1, 1, 0, 2, 0, 0, 2, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 2, 0, 0, 2, 2, 2, 2, 2},
+	{0, 0, 1, 1, 0, 1, 2, 2, 0, 1, 2, 2, 0, 0, 1, 1},
+	{0, 0, 0, 0, 2, 0, 0, 0, 2, 2, 1, 1, 2, 2, 2, 1},
+	{0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 2, 2, 2},
+	{0, 2, 2, 2, 0, 0, 2, 2, 0, 0, 1, 2, 0, 0, 1, 1},
+	{0, 0, 1, 1, 0, 0, 1, 2, 0, 0, 2, 2, 0, 2, 2, 2},
+	{0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0, 0, 1, 2, 0},
+	{0, 0, 0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 0, 0, 0, 0},
+	{0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2, 0, 1, 2, 0},
+	{0, 1, 2, 0, 2, 0, 1, 2, 1, 2, 0, 1, 0, 1, 2, 0},
+	{0, 0, 1, 1, 2, 2, 0, 0, 1, 1, 2, 2, 0, 0, 1, 1},
+	{0, 0, 1, 1, 1, 1, 2, 2, 2, 2, 0, 0, 0, 0, 1, 1},
+	{0, 1, 0, 1, 0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 2, 1, 2, 1, 2, 1},
+	{0, 0, 2, 2, 1, 1, 2, 2, 0, 0, 2, 2, 1, 1, 2, 2},
+	{0, 0, 2, 2, 0, 0, 1, 1, 0, 0, 2, 2, 0, 0, 1, 1},
+	{0, 2, 2, 0, 1, 2, 2, 1, 0, 2, 2, 0, 1, 2, 2, 1},
+	{0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2, 0, 1, 0, 1},
+	{0, 0, 0, 0, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1, 2, 1},
+	{0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 0, 1, 2, 2, 2, 2},
+	{0, 2, 2, 2, 0, 1, 1, 1, 0, 2, 2, 2, 0, 1, 1, 1},
+	{0, 0, 0, 2, 1, 1, 1, 2, 0, 0, 0, 2, 1, 1, 1, 2},
+	{0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 1, 2, 2, 1, 1, 2},
+	{0, 2, 2, 2, 0, 1, 1, 1, 0, 1, 1, 1, 0, 2, 2, 2},
+	{0, 0, 0, 2, 1, 1, 1, 2, 1, 1, 1, 2, 0, 0, 0, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2, 2, 1, 1, 2},
+	{0, 1, 1, 0, 0, 1, 1, 0, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 0, 2, 2, 0, 0, 1, 1, 0, 0, 1, 1, 0, 0, 2, 2},
+	{0, 0, 2, 2, 1, 1, 2, 2, 1, 1, 2, 2, 0, 0, 2, 2},
+	{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 2, 1, 1, 2},
+	{0, 0, 0, 2, 0, 0, 0, 1, 0, 0, 0, 2, 0, 0, 0, 1},
+	{0, 2, 2, 2, 1, 2, 2, 2, 0, 2, 2, 2, 1, 2, 2, 2},
+	{0, 1, 0, 1, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2, 2},
+	{0, 1, 1, 1, 2, 0, 1, 1, 2, 2, 0, 1, 2, 2, 2, 0},
+}
+
+// Anchor texels (whose index MSB is implicit) for subset 1 of two-subset blocks
+// and subsets 1 and 2 of three-subset blocks.
+var (
+	bc7Anchors2 = [64]uint8{
+		15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15,
+		15, 2, 8, 2, 2, 8, 8, 15, 2, 8, 2, 2, 8, 8, 2, 2,
+		15, 15, 6, 8, 2, 8, 15, 15, 2, 8, 2, 2, 2, 15, 15, 6,
+		6, 2, 6, 8, 15, 15, 2, 2, 15, 15, 15, 15, 15, 2, 2, 15,
+	}
+	bc7Anchors3a = [64]uint8{
+		3, 3, 15, 15, 8, 3, 15, 15, 8, 8, 6, 6, 6, 5, 3, 3,
+		3, 3, 8, 15, 3, 3, 6, 10, 5, 8, 8, 6, 8, 5, 15, 15,
+		8, 15, 3, 5, 6, 10, 8, 15, 15, 3, 15, 5, 15, 15, 15, 15,
+		3, 15, 5, 5, 5, 8, 5, 10, 5, 10, 8, 13, 15, 12, 3, 3,
+	}
+	bc7Anchors3b = [64]uint8{
+		15, 8, 8, 3, 15, 15, 3, 8, 15, 15, 15, 15, 15, 15, 15, 8,
+		15, 8, 15, 3, 15, 8, 15, 8, 3, 15, 6, 10, 15, 15, 10, 8,
+		15, 3, 15, 10, 10, 8, 9, 10, 6, 15, 8, 15, 3, 6, 6, 8,
+		15, 3, 15, 15, 15, 15, 15, 15, 15, 15, 15, 15, 3, 15, 15, 8,
+	}
+)
+
+// bitReader reads little-endian bit fields from a 128-bit BC7 block.
+type bitReader struct {
+	data []byte
+	pos  uint
+}
+
+func (r *bitReader) read(n int) int {
+	v := 0
+	for i := 0; i < n; i++ {
+		bit := (r.data[r.pos>>3] >> (r.pos & 7)) & 1
+		v |= int(bit) << uint(i)
+		r.pos++
+	}
+	return v
+}
+
+// bc7Subset returns the subset a texel belongs to for the given partition.
+func bc7Subset(subsets, partition, texel int) int {
+	switch subsets {
+	case 2:
+		return int(bc7Partitions2[partition]>>uint(texel)) & 1
+	case 3:
+		return int(bc7Partitions3[partition][texel])
+	default:
+		return 0
+	}
+}
+
+// bc7IsAnchor reports whether a texel stores its index with one bit fewer.
+func bc7IsAnchor(subsets, partition, texel int) bool {
+	if texel == 0 {
+		return true
+	}
+	switch subsets {
+	case 2:
+		return texel == int(bc7Anchors2[partition])
+	case 3:
+		return texel == int(bc7Anchors3a[partition]) || texel == int(bc7Anchors3b[partition])
+	default:
+		return false
+	}
+}
+
+// decodeBC7Block decodes a single 16-byte BC7 block.
+func decodeBC7Block(block []byte, out *[16][4]byte) {
+	modeIndex := 0
+	for modeIndex < 8 && block[0]&(1<<uint(modeIndex)) == 0 {
+		modeIndex++
+	}
+	if modeIndex == 8 {
+		// Reserved mode: the spec mandates transparent black.
+		*out = [16][4]byte{}
+		return
+	}
+	mode := bc7Modes[modeIndex]
+	r := &bitReader{data: block, pos: uint(modeIndex + 1)}
+
+	partition := r.read(mode.partitionBits)
+	rotation := r.read(mode.rotationBits)
+	indexSel := r.read(mode.indexSelBits)
+
+	endpoints := mode.subsets * 2
+	var ep [6][4]int
+	for ch := 0; ch < 3; ch++ {
+		for e := 0; e < endpoints; e++ {
+			ep[e][ch] = r.read(mode.colorBits)
+		}
+	}
+	for e := 0; e < endpoints; e++ {
+		if mode.alphaBits > 0 {
+			ep[e][3] = r.read(mode.alphaBits)
+		}
+	}
+
+	colorBits, alphaBits := mode.colorBits, mode.alphaBits
+	switch {
+	case mode.endpointPBits:
+		for e := 0; e < endpoints; e++ {
+			p := r.read(1)
+			for ch := 0; ch < 4; ch++ {
+				ep[e][ch] = ep[e][ch]<<1 | p
+			}
+		}
+		colorBits++
+		if alphaBits > 0 {
+			alphaBits++
+		}
+	case mode.sharedPBits:
+		for s := 0; s < mode.subsets; s++ {
+			p := r.read(1)
+			for ch := 0; ch < 4; ch++ {
+				ep[2*s][ch] = ep[2*s][ch]<<1 | p
+				ep[2*s+1][ch] = ep[2*s+1][ch]<<1 | p
+			}
+		}
+		colorBits++
+	}
+	for e := 0; e < endpoints; e++ {
+		for ch := 0; ch < 3; ch++ {
+			ep[e][ch] = expandBits(ep[e][ch], colorBits)
+		}
+		if alphaBits > 0 {
+			ep[e][3] = expandBits(ep[e][3], alphaBits)
+		} else {
+			ep[e][3] = 255
+		}
+	}
+
+	var indices, indices2 [16]int
+	for i := 0; i < 16; i++ {
+		bits := mode.indexBits
+		if bc7IsAnchor(mode.subsets, partition, i) {
+			bits--
+		}
+		indices[i] = r.read(bits)
+	}
+	if mode.indexBits2 > 0 {
+		for i := 0; i < 16; i++ {
+			bits := mode.indexBits2
+			if i == 0 {
+				bits--
+			}
+			indices2[i] = r.read(bits)
+		}
+	}
+
+	for i := 0; i < 16; i++ {
+		s := bc7Subset(mode.subsets, partition, i)
+		e0, e1 := ep[2*s], ep[2*s+1]
+		var px [4]int
+		if mode.indexBits2 > 0 {
+			colorIdx, colorBitsN := indices[i], mode.indexBits
+			alphaIdx, alphaBitsN := indices2[i], mode.indexBits2
+			if indexSel == 1 {
+				colorIdx, alphaIdx = alphaIdx, colorIdx
+				colorBitsN, alphaBitsN = alphaBitsN, colorBitsN
+			}
+			cw := bc7Weights[colorBitsN][colorIdx]
+			aw := bc7Weights[alphaBitsN][alphaIdx]
+			for ch := 0; ch < 3; ch++ {
+				px[ch] = bc7Interpolate(e0[ch], e1[ch], cw)
+			}
+			px[3] = bc7Interpolate(e0[3], e1[3], aw)
+		} else {
+			w := bc7Weights[mode.indexBits][indices[i]]
+			for ch := 0; ch < 4; ch++ {
+				px[ch] = bc7Interpolate(e0[ch], e1[ch], w)
+			}
+		}
+		switch rotation {
+		case 1:
+			px[0], px[3] = px[3], px[0]
+		case 2:
+			px[1], px[3] = px[3], px[1]
+		case 3:
+			px[2], px[3] = px[3], px[2]
+		}
+		out[i] = [4]byte{byte(px[0]), byte(px[1]), byte(px[2]), byte(px[3])}
+	}
+}
+
+// expandBits replicates the high bits of an n-bit value to fill 8 bits.
+func expandBits(v, n int) int {
+	v <<= uint(8 - n)
+	return v | v>>uint(n)
+}
+
+// bc7Interpolate blends two endpoints with a 6-bit BC7 weight.
+func bc7Interpolate(e0, e1, w int) int {
+	return ((64-w)*e0 + w*e1 + 32) >> 6
+}
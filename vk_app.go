@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"net/http"
 	"os"
 	"reflect"
 	"runtime"
@@ -28,7 +29,7 @@ import (
 
 const (
 	maxFramesInFlight  = 2
-	maxOverlayVertices = 1500
+	maxOverlayVertices = 6 * 8192 // one quad per glyph or panel
 )
 
 var (
@@ -40,11 +41,13 @@ type vertex struct {
 	pos   mgl32.Vec3
 	color mgl32.Vec3
 	uv    mgl32.Vec2
+	face  uint32
 }
 
 type overlayVertex struct {
 	pos   mgl32.Vec2
-	color mgl32.Vec3
+	uv    mgl32.Vec2
+	color mgl32.Vec4 // alpha multiplies the atlas coverage
 }
 
 type cString struct {
@@ -83,40 +86,60 @@ func freeCStrings(ptrs []*C.char) {
 	}
 }
 
+// Cube faces in Vulkan cube-map layer order; each face samples its own texture array layer.
+const (
+	cubeFacePosX uint32 = iota
+	cubeFaceNegX
+	cubeFacePosY
+	cubeFaceNegY
+	cubeFacePosZ
+	cubeFaceNegZ
+	cubeFaceCount
+)
+
 // 24 vertices (4 per face) to allow correct UVs per face.
 var cubeVertices = []vertex{
 	// Back face (Z-)
-	{pos: mgl32.Vec3{-1, -1, -1}, color: mgl32.Vec3{1, 0, 0}, uv: mgl32.Vec2{0, 0}},
-	{pos: mgl32.Vec3{1, -1, -1}, color: mgl32.Vec3{1, 0, 0}, uv: mgl32.Vec2{1, 0}},
-	{pos: mgl32.Vec3{1, 1, -1}, color: mgl32.Vec3{1, 0, 0}, uv: mgl32.Vec2{1, 1}},
-	{pos: mgl32.Vec3{-1, 1, -1}, color: mgl32.Vec3{1, 0, 0}, uv: mgl32.Vec2{0, 1}},
+	{pos: mgl32.Vec3{-1, -1, -1}, color: mgl32.Vec3{1, 0, 0}, uv: mgl32.Vec2{0, 0}, face: cubeFaceNegZ},
+	{pos: mgl32.Vec3{1, -1, -1}, color: mgl32.Vec3{1, 0, 0}, uv: mgl32.Vec2{1, 0}, face: cubeFaceNegZ},
+	{pos: mgl32.Vec3{1, 1, -1}, color: mgl32.Vec3{1, 0, 0}, uv: mgl32.Vec2{1, 1}, face: cubeFaceNegZ},
+	{pos: mgl32.Vec3{-1, 1, -1}, color: mgl32.Vec3{1, 0, 0}, uv: mgl32.Vec2{0, 1}, face: cubeFaceNegZ},
 	// Front face (Z+)
-	{pos: mgl32.Vec3{-1, -1, 1}, color: mgl32.Vec3{0, 1, 0}, uv: mgl32.Vec2{0, 0}},
-	{pos: mgl32.Vec3{1, -1, 1}, color: mgl32.Vec3{0, 1, 0}, uv: mgl32.Vec2{1, 0}},
-	{pos: mgl32.Vec3{1, 1, 1}, color: mgl32.Vec3{0, 1, 0}, uv: mgl32.Vec2{1, 1}},
-	{pos: mgl32.Vec3{-1, 1, 1}, color: mgl32.Vec3{0, 1, 0}, uv: mgl32.Vec2{0, 1}},
+	{pos: mgl32.Vec3{-1, -1, 1}, color: mgl32.Vec3{0, 1, 0}, uv: mgl32.Vec2{0, 0}, face: cubeFacePosZ},
+	{pos: mgl32.Vec3{1, -1, 1}, color: mgl32.Vec3{0, 1, 0}, uv: mgl32.Vec2{1, 0}, face: cubeFacePosZ},
+	{pos: mgl32.Vec3{1, 1, 1}, color: mgl32.Vec3{0, 1, 0}, uv: mgl32.Vec2{1, 1}, face: cubeFacePosZ},
+	{pos: mgl32.Vec3{-1, 1, 1}, color: mgl32.Vec3{0, 1, 0}, uv: mgl32.Vec2{0, 1}, face: cubeFacePosZ},
 	// Bottom face (Y-)
-	{pos: mgl32.Vec3{-1, -1, -1}, color: mgl32.Vec3{0, 0, 1}, uv: mgl32.Vec2{0, 0}},
-	{pos: mgl32.Vec3{1, -1, -1}, color: mgl32.Vec3{0, 0, 1}, uv: mgl32.Vec2{1, 0}},
-	{pos: mgl32.Vec3{1, -1, 1}, color: mgl32.Vec3{0, 0, 1}, uv: mgl32.Vec2{1, 1}},
-	{pos: mgl32.Vec3{-1, -1, 1}, color: mgl32.Vec3{0, 0, 1}, uv: mgl32.Vec2{0, 1}},
+	{pos: mgl32.Vec3{-1, -1, -1}, color: mgl32.Vec3{0, 0, 1}, uv: mgl32.Vec2{0, 0}, face: cubeFaceNegY},
+	{pos: mgl32.Vec3{1, -1, -1}, color: mgl32.Vec3{0, 0, 1}, uv: mgl32.Vec2{1, 0}, face: cubeFaceNegY},
+	{pos: mgl32.Vec3{1, -1, 1}, color: mgl32.Vec3{0, 0, 1}, uv: mgl32.Vec2{1, 1}, face: cubeFaceNegY},
+	{pos: mgl32.Vec3{-1, -1, 1}, color: mgl32.Vec3{0, 0, 1}, uv: mgl32.Vec2{0, 1}, face: cubeFaceNegY},
 	// Top face (Y+)
-	{pos: mgl32.Vec3{-1, 1, -1}, color: mgl32.Vec3{1, 1, 0}, uv: mgl32.Vec2{0, 0}},
-	{pos: mgl32.Vec3{1, 1, -1}, color: mgl32.Vec3{1, 1, 0}, uv: mgl32.Vec2{1, 0}},
-	{pos: mgl32.Vec3{1, 1, 1}, color: mgl32.Vec3{1, 1, 0}, uv: mgl32.Vec2{1, 1}},
-	{pos: mgl32.Vec3{-1, 1, 1}, color: mgl32.Vec3{1, 1, 0}, uv: mgl32.Vec2{0, 1}},
+	{pos: mgl32.Vec3{-1, 1, -1}, color: mgl32.Vec3{1, 1, 0}, uv: mgl32.Vec2{0, 0}, face: cubeFacePosY},
+	{pos: mgl32.Vec3{1, 1, -1}, color: mgl32.Vec3{1, 1, 0}, uv: mgl32.Vec2{1, 0}, face: cubeFacePosY},
+	{pos: mgl32.Vec3{1, 1, 1}, color: mgl32.Vec3{1, 1, 0}, uv: mgl32.Vec2{1, 1}, face: cubeFacePosY},
+	{pos: mgl32.Vec3{-1, 1, 1}, color: mgl32.Vec3{1, 1, 0}, uv: mgl32.Vec2{0, 1}, face: cubeFacePosY},
 	// Left face (X-)
-	{pos: mgl32.Vec3{-1, -1, 1}, color: mgl32.Vec3{1, 0, 1}, uv: mgl32.Vec2{0, 0}},
-	{pos: mgl32.Vec3{-1, -1, -1}, color: mgl32.Vec3{1, 0, 1}, uv: mgl32.Vec2{1, 0}},
-	{pos: mgl32.Vec3{-1, 1, -1}, color: mgl32.Vec3{1, 0, 1}, uv: mgl32.Vec2{1, 1}},
-	{pos: mgl32.Vec3{-1, 1, 1}, color: mgl32.Vec3{1, 0, 1}, uv: mgl32.Vec2{0, 1}},
+	{pos: mgl32.Vec3{-1, -1, 1}, color: mgl32.Vec3{1, 0, 1}, uv: mgl32.Vec2{0, 0}, face: cubeFaceNegX},
+	{pos: mgl32.Vec3{-1, -1, -1}, color: mgl32.Vec3{1, 0, 1}, uv: mgl32.Vec2{1, 0}, face: cubeFaceNegX},
+	{pos: mgl32.Vec3{-1, 1, -1}, color: mgl32.Vec3{1, 0, 1}, uv: mgl32.Vec2{1, 1}, face: cubeFaceNegX},
+	{pos: mgl32.Vec3{-1, 1, 1}, color: mgl32.Vec3{1, 0, 1}, uv: mgl32.Vec2{0, 1}, face: cubeFaceNegX},
 	// Right face (X+)
-	{pos: mgl32.Vec3{1, -1, -1}, color: mgl32.Vec3{0, 1, 1}, uv: mgl32.Vec2{0, 0}},
-	{pos: mgl32.Vec3{1, -1, 1}, color: mgl32.Vec3{0, 1, 1}, uv: mgl32.Vec2{1, 0}},
-	{pos: mgl32.Vec3{1, 1, 1}, color: mgl32.Vec3{0, 1, 1}, uv: mgl32.Vec2{1, 1}},
-	{pos: mgl32.Vec3{1, 1, -1}, color: mgl32.Vec3{0, 1, 1}, uv: mgl32.Vec2{0, 1}},
+	{pos: mgl32.Vec3{1, -1, -1}, color: mgl32.Vec3{0, 1, 1}, uv: mgl32.Vec2{0, 0}, face: cubeFacePosX},
+	{pos: mgl32.Vec3{1, -1, 1}, color: mgl32.Vec3{0, 1, 1}, uv: mgl32.Vec2{1, 0}, face: cubeFacePosX},
+	{pos: mgl32.Vec3{1, 1, 1}, color: mgl32.Vec3{0, 1, 1}, uv: mgl32.Vec2{1, 1}, face: cubeFacePosX},
+	{pos: mgl32.Vec3{1, 1, -1}, color: mgl32.Vec3{0, 1, 1}, uv: mgl32.Vec2{0, 1}, face: cubeFacePosX},
 }
 
+// cubeNormals are the outward normals of cubeVertices, four per face in the same order.
+var cubeNormals = func() []mgl32.Vec3 {
+	var out []mgl32.Vec3
+	for _, n := range []mgl32.Vec3{{0, 0, -1}, {0, 0, 1}, {0, -1, 0}, {0, 1, 0}, {-1, 0, 0}, {1, 0, 0}} {
+		out = append(out, n, n, n, n)
+	}
+	return out
+}()
+
 var cubeIndices = []uint32{
 	0, 1, 2, 2, 3, 0, // back
 	4, 5, 6, 6, 7, 4, // front
@@ -136,12 +159,98 @@ type appConfig struct {
 	enableValidation bool
 	vsyncEnabled     bool
 	maxFPS           int
+	texturePath      string
+	faceTexturePaths []string
+	skyboxPath       string
+	skyboxFacePaths  []string
+	meshPath         string
+	animationPath    string
+	hudScale         float32
+	hudVerbosity     hudVerbosity
+	hudPanels        map[string]hudOverride
+	hudBackground    mgl32.Vec4
+	camera           cameraConfig
+	rotation         rotationConfig
+	clock            clockConfig
+	keys             *keyMap
+	gamepad          gamepadConfig
+	window           windowConfig
+	rememberState    bool
+	bench            benchConfig
+	inputRecordPath  string
+	statsExportPath  string
+	metricsAddr      string
+	inputReplayPath  string
 }
 
 type fileConfig struct {
-	Validation *bool `yaml:"validation"`
-	Vsync      *bool `yaml:"vsync"`
-	MaxFPS     *int  `yaml:"max_fps"`
+	Validation    *bool     `yaml:"validation"`
+	Vsync         *bool     `yaml:"vsync"`
+	MaxFPS        *int      `yaml:"max_fps"`
+	Texture       *string   `yaml:"texture"`
+	Faces         []string  `yaml:"face_textures"`
+	Skybox        *string   `yaml:"skybox"`
+	SkyboxFace    []string  `yaml:"skybox_faces"`
+	Mesh          *string   `yaml:"mesh"`
+	Animation     *string   `yaml:"animation"`
+	HUDScale      *float32  `yaml:"hud_scale"`
+	HUDVerbosity  *string   `yaml:"hud_verbosity"`
+	HUDBackground []float32 `yaml:"hud_background"`
+	HUDPanels     map[string]struct {
+		Anchor    *string   `yaml:"anchor"`
+		Color     []float32 `yaml:"color"`
+		Scale     *float32  `yaml:"scale"`
+		Verbosity *string   `yaml:"verbosity"`
+	} `yaml:"hud_panels"`
+	Camera struct {
+		FOV              *float32 `yaml:"fov"`
+		Near             *float32 `yaml:"near"`
+		Far              *float32 `yaml:"far"`
+		Damping          *float32 `yaml:"damping"`
+		FlySpeed         *float32 `yaml:"fly_speed"`
+		MouseSensitivity *float32 `yaml:"mouse_sensitivity"`
+	} `yaml:"camera"`
+	Rotation struct {
+		Mode      *string   `yaml:"mode"`
+		Axis      []float32 `yaml:"axis"`
+		Speed     *float32  `yaml:"speed"`
+		Direction *string   `yaml:"direction"`
+	} `yaml:"rotation"`
+	Clock struct {
+		StepHz    *float64 `yaml:"step_hz"`
+		TimeScale *float64 `yaml:"time_scale"`
+	} `yaml:"clock"`
+	RememberState *bool `yaml:"remember_state"`
+	Bench         struct {
+		Warmup *int    `yaml:"warmup"`
+		Frames *int    `yaml:"frames"`
+		Output *string `yaml:"output"`
+	} `yaml:"bench"`
+	Window struct {
+		Mode        *string `yaml:"mode"`
+		Fullscreen  *string `yaml:"fullscreen"`
+		Monitor     *int    `yaml:"monitor"`
+		Width       *int    `yaml:"width"`
+		Height      *int    `yaml:"height"`
+		VideoWidth  *int    `yaml:"video_width"`
+		VideoHeight *int    `yaml:"video_height"`
+		RefreshRate *int    `yaml:"refresh_rate"`
+	} `yaml:"window"`
+	Keybindings map[string]stringList `yaml:"keybindings"`
+	InputRecord *string               `yaml:"input_record"`
+	StatsExport *string               `yaml:"stats_export"`
+	MetricsAddr *string               `yaml:"metrics_addr"`
+	InputReplay *string               `yaml:"input_replay"`
+	Gamepad     struct {
+		Enabled   *bool          `yaml:"enabled"`
+		Joystick  *int           `yaml:"joystick"`
+		Deadzone  *float32       `yaml:"deadzone"`
+		LookSpeed *float32       `yaml:"look_speed"`
+		ZoomSpeed *float32       `yaml:"zoom_speed"`
+		InvertY   *bool          `yaml:"invert_y"`
+		Axes      map[string]int `yaml:"axes"`
+		Buttons   map[int]string `yaml:"buttons"`
+	} `yaml:"gamepad"`
 }
 
 type queueFamilyIndices struct {
@@ -160,6 +269,7 @@ type swapchainSupport struct {
 type VulkanApp struct {
 	cfg                       appConfig
 	window                    *glfw.Window
+	display                   *windowState
 	instance                  vulkan.Instance
 	debugCallback             vulkan.DebugReportCallback
 	surface                   vulkan.Surface
@@ -182,19 +292,48 @@ type VulkanApp struct {
 	pipeline                  vulkan.Pipeline
 	overlayPipelineLayout     vulkan.PipelineLayout
 	overlayPipeline           vulkan.Pipeline
+	overlaySetLayout          vulkan.DescriptorSetLayout
+	overlayDescriptorPool     vulkan.DescriptorPool
+	overlayDescriptorSet      vulkan.DescriptorSet
+	overlayAtlasImage         vulkan.Image
+	overlayAtlasMemory        vulkan.DeviceMemory
+	overlayAtlasView          vulkan.ImageView
+	overlayAtlasSampler       vulkan.Sampler
 	descriptorSetLayout       vulkan.DescriptorSetLayout
+	materialSetLayout         vulkan.DescriptorSetLayout
+	materialDescriptorPool    vulkan.DescriptorPool
+	materialSets              []vulkan.DescriptorSet
+	materialTextures          []materialTexture
 	descriptorPool            vulkan.DescriptorPool
 	descriptorSets            []vulkan.DescriptorSet
 	uniformBuffers            []vulkan.Buffer
 	uniformBuffersMemory      []vulkan.DeviceMemory
 	textureImage              vulkan.Image
 	textureImageMemory        vulkan.DeviceMemory
+	textureFormat             vulkan.Format
+	textureMipLevels          uint32
+	textureLayers             uint32
 	textureImageView          vulkan.ImageView
 	textureSampler            vulkan.Sampler
+	skyboxImage               vulkan.Image
+	skyboxImageMemory         vulkan.DeviceMemory
+	skyboxImageView           vulkan.ImageView
+	skyboxSampler             vulkan.Sampler
+	skyboxDescriptorSetLayout vulkan.DescriptorSetLayout
+	skyboxDescriptorPool      vulkan.DescriptorPool
+	skyboxDescriptorSet       vulkan.DescriptorSet
+	skyboxPipelineLayout      vulkan.PipelineLayout
+	skyboxPipeline            vulkan.Pipeline
+	skyboxVertexBuffer        vulkan.Buffer
+	skyboxVertexMemory        vulkan.DeviceMemory
+	skyboxVertexCount         uint32
+	skyboxViewProj            mgl32.Mat4
 	vertexBuffer              vulkan.Buffer
 	vertexBufferMemory        vulkan.DeviceMemory
 	indexBuffer               vulkan.Buffer
 	indexBufferMemory         vulkan.DeviceMemory
+	mesh                      mesh
+	indexType                 vulkan.IndexType
 	overlayVertexBuffer       vulkan.Buffer
 	overlayVertexBufferMemory vulkan.DeviceMemory
 	overlayIndirectBuffer     vulkan.Buffer
@@ -207,32 +346,89 @@ type VulkanApp struct {
 	inFlightFences            []vulkan.Fence
 	imagesInFlight            []vulkan.Fence
 	currentFrame              int
-	debugFrames               int
 	framebufferResized        bool
-	startTime                 time.Time
-	paused                    bool
-	pausedAt                  time.Time
-	pausedAccum               time.Duration
 	fpsFrameCount             int
 	fpsLastTime               time.Time
 	fpsValue                  float64
+	frameTime                 time.Duration
+	lastFrameAt               time.Time
+	frameTimes                frameTimeHistory
+	gpuQueryPool              vulkan.QueryPool
+	gpuTimestampBits          uint32
+	gpuTimestampPeriod        float32 // nanoseconds per timestamp tick
+	gpuStampsWritten          [maxFramesInFlight]bool
+	gpuStampBenchFrame        [maxFramesInFlight]int // bench frame each slot's stamps belong to
+	gpuTimes                  gpuPassTimes
+	gpuFrameTimes             frameTimeHistory
+	pipelineStatsSupported    bool
+	statsQueryPool            vulkan.QueryPool
+	statsWritten              [maxFramesInFlight]bool
+	pipelineStats             pipelineStats
+	bench                     *benchRun
+	statsWriter               *frameStatsWriter
+	statsFrames               uint64
+	frameStamps               frameStamps
+	swapchainRecreates        uint64
+	metrics                   *metrics
+	metricsServer             *http.Server
+	gpuAllocations            map[vulkan.DeviceMemory]uint64
+	gpuAllocated              uint64
+	hud                       *hud
+	deviceName                string
+	deviceProps               vulkan.PhysicalDeviceProperties
+	presentMode               vulkan.PresentMode
 	overlayVertexCount        uint32
+	camera                    *cameraRig
+	lastCameraUpdate          time.Time
+	rotation                  *rotator
+	clock                     *simClock
+	animation                 *keyframeAnimation
+	swapchainCanCapture       bool
+	screenshotPending         bool
+	hudHidden                 bool
+	helpVisible               bool
+	inputRecorder             *inputRecorder
+	replay                    *inputReplayer
 }
 
 // newVulkanApp wires configuration, creates the Vulkan app, and performs all initialization.
-func newVulkanApp(window *glfw.Window) (*VulkanApp, error) {
-	cfg := loadAppConfig()
-
+func newVulkanApp(window *glfw.Window, cfg appConfig, display *windowState) (*VulkanApp, error) {
 	app := &VulkanApp{
-		cfg:    cfg,
-		window: window,
+		cfg:      cfg,
+		window:   window,
+		display:  display,
+		camera:   newCameraRig(cfg.camera),
+		rotation: newRotator(cfg.rotation),
+		clock:    newSimClock(cfg.clock),
+		hud:      newHUD(cfg.hudVerbosity, cfg.hudPanels),
 	}
+	app.registerHUDPanels()
 
 	log.Printf("config: validation=%v vsync=%v maxFPS=%d", cfg.enableValidation, cfg.vsyncEnabled, cfg.maxFPS)
 
+	app.startInputSession()
+	app.startFrameStats()
+	if cfg.metricsAddr != "" {
+		app.metrics = newMetrics()
+	}
+
+	if cfg.animationPath != "" {
+		anim, err := loadKeyframeAnimation(cfg.animationPath)
+		if err != nil {
+			log.Printf("animation: %v (using rotation settings)", err)
+		} else {
+			app.animation = anim
+			log.Printf("animation: loaded %s (%.2fs, loop=%v)", cfg.animationPath, anim.duration, anim.loop)
+		}
+	}
+
 	if err := app.initVulkan(); err != nil {
 		return nil, err
 	}
+	app.startMetricsServer()
+	if cfg.bench.enabled {
+		app.bench = newBenchRun(cfg.bench)
+	}
 	return app, nil
 }
 
@@ -250,6 +446,17 @@ func loadAppConfig() appConfig {
 		enableValidation: true,
 		vsyncEnabled:     false,
 		maxFPS:           0,
+		camera:           defaultCameraConfig(),
+		rotation:         defaultRotationConfig(),
+		clock:            defaultClockConfig(),
+		keys:             defaultKeyMap(),
+		gamepad:          defaultGamepadConfig(),
+		window:           defaultWindowConfig(),
+		rememberState:    true,
+		bench:            defaultBenchConfig(),
+		hudScale:         1,
+		hudVerbosity:     hudNormal,
+		hudBackground:    mgl32.Vec4{0, 0, 0, 0.55},
 	}
 
 	path := configPath()
@@ -280,30 +487,398 @@ func loadAppConfig() appConfig {
 			cfg.maxFPS = *fc.MaxFPS
 		}
 	}
+	if fc.Texture != nil {
+		cfg.texturePath = strings.TrimSpace(*fc.Texture)
+	}
+	if len(fc.Faces) > 0 {
+		if len(fc.Faces) != int(cubeFaceCount) {
+			log.Printf("config: face_textures needs %d entries (got %d); ignoring", cubeFaceCount, len(fc.Faces))
+		} else {
+			for _, f := range fc.Faces {
+				cfg.faceTexturePaths = append(cfg.faceTexturePaths, strings.TrimSpace(f))
+			}
+		}
+	}
+
+	if fc.Camera.FOV != nil {
+		if *fc.Camera.FOV <= 0 || *fc.Camera.FOV >= 180 {
+			log.Printf("config: camera.fov must be in (0, 180) (got %g); keeping %g", *fc.Camera.FOV, cfg.camera.fovDeg)
+		} else {
+			cfg.camera.fovDeg = *fc.Camera.FOV
+		}
+	}
+	if fc.Camera.Near != nil {
+		cfg.camera.near = *fc.Camera.Near
+	}
+	if fc.Camera.Far != nil {
+		cfg.camera.far = *fc.Camera.Far
+	}
+	if cfg.camera.near <= 0 || cfg.camera.far <= cfg.camera.near {
+		log.Printf("config: camera needs 0 < near < far (got %g, %g); using defaults", cfg.camera.near, cfg.camera.far)
+		def := defaultCameraConfig()
+		cfg.camera.near, cfg.camera.far = def.near, def.far
+	}
+	if fc.Camera.Damping != nil {
+		if *fc.Camera.Damping < 0 {
+			log.Printf("config: camera.damping must be >= 0 (got %g); keeping %g", *fc.Camera.Damping, cfg.camera.damping)
+		} else {
+			cfg.camera.damping = *fc.Camera.Damping
+		}
+	}
+	if fc.Camera.FlySpeed != nil {
+		if *fc.Camera.FlySpeed <= 0 {
+			log.Printf("config: camera.fly_speed must be > 0 (got %g); keeping %g", *fc.Camera.FlySpeed, cfg.camera.flySpeed)
+		} else {
+			cfg.camera.flySpeed = *fc.Camera.FlySpeed
+		}
+	}
+	if fc.Camera.MouseSensitivity != nil {
+		if *fc.Camera.MouseSensitivity <= 0 {
+			log.Printf("config: camera.mouse_sensitivity must be > 0 (got %g); keeping %g", *fc.Camera.MouseSensitivity, cfg.camera.mouseSensitivity)
+		} else {
+			cfg.camera.mouseSensitivity = *fc.Camera.MouseSensitivity
+		}
+	}
+	if fc.Rotation.Mode != nil {
+		if mode, err := parseAnimationMode(*fc.Rotation.Mode); err != nil {
+			log.Printf("config: rotation.mode: %v; keeping %s", err, cfg.rotation.mode)
+		} else {
+			cfg.rotation.mode = mode
+		}
+	}
+	if fc.Rotation.Axis != nil {
+		axis := mgl32.Vec3{}
+		if len(fc.Rotation.Axis) == 3 {
+			axis = mgl32.Vec3{fc.Rotation.Axis[0], fc.Rotation.Axis[1], fc.Rotation.Axis[2]}
+		}
+		if axis.Len() == 0 {
+			log.Printf("config: rotation.axis must be a non-zero [x, y, z] (got %v); keeping %v", fc.Rotation.Axis, cfg.rotation.axis)
+		} else {
+			cfg.rotation.axis = axis.Normalize()
+		}
+	}
+	if fc.Rotation.Speed != nil {
+		if *fc.Rotation.Speed < 0 {
+			log.Printf("config: rotation.speed must be >= 0 (got %g); keeping %g", *fc.Rotation.Speed, cfg.rotation.degPerSec)
+		} else {
+			cfg.rotation.degPerSec = *fc.Rotation.Speed
+		}
+	}
+	if fc.Rotation.Direction != nil {
+		switch strings.ToLower(strings.TrimSpace(*fc.Rotation.Direction)) {
+		case "ccw":
+			cfg.rotation.clockwise = false
+		case "cw":
+			cfg.rotation.clockwise = true
+		default:
+			log.Printf("config: rotation.direction must be cw or ccw (got %q); keeping default", *fc.Rotation.Direction)
+		}
+	}
+	if fc.Clock.StepHz != nil {
+		if *fc.Clock.StepHz < 1 || *fc.Clock.StepHz > 1000 {
+			log.Printf("config: clock.step_hz must be in [1, 1000] (got %g); keeping %g", *fc.Clock.StepHz, cfg.clock.stepHz)
+		} else {
+			cfg.clock.stepHz = *fc.Clock.StepHz
+		}
+	}
+	if fc.Clock.TimeScale != nil {
+		if *fc.Clock.TimeScale < clockMinScale || *fc.Clock.TimeScale > clockMaxScale {
+			log.Printf("config: clock.time_scale must be in [%g, %g] (got %g); keeping %g", clockMinScale, clockMaxScale, *fc.Clock.TimeScale, cfg.clock.timeScale)
+		} else {
+			cfg.clock.timeScale = *fc.Clock.TimeScale
+		}
+	}
+	if fc.HUDScale != nil {
+		if *fc.HUDScale < 0.25 || *fc.HUDScale > 8 {
+			log.Printf("config: hud_scale must be in [0.25, 8] (got %g); keeping %g", *fc.HUDScale, cfg.hudScale)
+		} else {
+			cfg.hudScale = *fc.HUDScale
+		}
+	}
+	if fc.HUDVerbosity != nil {
+		if v, err := parseHUDVerbosity(*fc.HUDVerbosity); err != nil {
+			log.Printf("config: hud_verbosity: %v; keeping %s", err, cfg.hudVerbosity)
+		} else {
+			cfg.hudVerbosity = v
+		}
+	}
+	if fc.HUDBackground != nil {
+		if len(fc.HUDBackground) != 4 {
+			log.Printf("config: hud_background needs 4 components (r, g, b, a; got %d); keeping %v", len(fc.HUDBackground), cfg.hudBackground)
+		} else {
+			cfg.hudBackground = mgl32.Vec4{fc.HUDBackground[0], fc.HUDBackground[1], fc.HUDBackground[2], fc.HUDBackground[3]}
+		}
+	}
+	for name, p := range fc.HUDPanels {
+		var o hudOverride
+		if p.Anchor != nil {
+			if anchor, err := parseHUDAnchor(*p.Anchor); err != nil {
+				log.Printf("config: hud_panels.%s.anchor: %v; keeping the panel's own", name, err)
+			} else {
+				o.anchor = &anchor
+			}
+		}
+		if p.Color != nil {
+			if len(p.Color) != 3 {
+				log.Printf("config: hud_panels.%s.color needs 3 components (got %d); keeping the panel's own", name, len(p.Color))
+			} else {
+				c := mgl32.Vec3{p.Color[0], p.Color[1], p.Color[2]}
+				o.color = &c
+			}
+		}
+		if p.Scale != nil {
+			if *p.Scale < 0.5 || *p.Scale > 16 {
+				log.Printf("config: hud_panels.%s.scale must be in [0.5, 16] (got %g); keeping the panel's own", name, *p.Scale)
+			} else {
+				scale := *p.Scale
+				o.scale = &scale
+			}
+		}
+		if p.Verbosity != nil {
+			if v, err := parseHUDVerbosity(*p.Verbosity); err != nil {
+				log.Printf("config: hud_panels.%s.verbosity: %v; keeping the panel's own", name, err)
+			} else {
+				o.verbosity = &v
+			}
+		}
+		if cfg.hudPanels == nil {
+			cfg.hudPanels = make(map[string]hudOverride)
+		}
+		cfg.hudPanels[name] = o
+	}
+	if fc.RememberState != nil {
+		cfg.rememberState = *fc.RememberState
+	}
+	if fc.Bench.Warmup != nil {
+		if *fc.Bench.Warmup < 0 {
+			log.Printf("config: bench.warmup must be >= 0 (got %d); keeping %d", *fc.Bench.Warmup, cfg.bench.warmup)
+		} else {
+			cfg.bench.warmup = *fc.Bench.Warmup
+		}
+	}
+	if fc.Bench.Frames != nil {
+		if *fc.Bench.Frames < 1 {
+			log.Printf("config: bench.frames must be >= 1 (got %d); keeping %d", *fc.Bench.Frames, cfg.bench.frames)
+		} else {
+			cfg.bench.frames = *fc.Bench.Frames
+		}
+	}
+	if fc.Bench.Output != nil && strings.TrimSpace(*fc.Bench.Output) != "" {
+		cfg.bench.output = strings.TrimSpace(*fc.Bench.Output)
+	}
+	if fc.Window.Mode != nil {
+		if mode, err := parseWindowMode(*fc.Window.Mode); err != nil {
+			log.Printf("config: window.mode: %v; keeping %s", err, cfg.window.mode)
+		} else {
+			cfg.window.mode = mode
+		}
+	}
+	if cfg.window.mode != windowWindowed {
+		cfg.window.toggleMode = cfg.window.mode
+	}
+	if fc.Window.Fullscreen != nil {
+		if mode, err := parseWindowMode(*fc.Window.Fullscreen); err != nil || mode == windowWindowed {
+			log.Printf("config: window.fullscreen must be borderless or fullscreen (got %q); keeping %s", *fc.Window.Fullscreen, cfg.window.toggleMode)
+		} else {
+			cfg.window.toggleMode = mode
+		}
+	}
+	if fc.Window.Monitor != nil {
+		if *fc.Window.Monitor < 0 {
+			log.Printf("config: window.monitor must be 0 (follow the window) or 1-n (got %d); keeping %d", *fc.Window.Monitor, cfg.window.monitor)
+		} else {
+			cfg.window.monitor = *fc.Window.Monitor
+		}
+	}
+	if fc.Window.Width != nil || fc.Window.Height != nil {
+		width, height := cfg.window.width, cfg.window.height
+		if fc.Window.Width != nil {
+			width = *fc.Window.Width
+		}
+		if fc.Window.Height != nil {
+			height = *fc.Window.Height
+		}
+		if width < 64 || height < 64 {
+			log.Printf("config: window size must be at least 64x64 (got %dx%d); keeping %dx%d", width, height, cfg.window.width, cfg.window.height)
+		} else {
+			cfg.window.width, cfg.window.height = width, height
+		}
+	}
+	if fc.Window.VideoWidth != nil && fc.Window.VideoHeight != nil {
+		if *fc.Window.VideoWidth <= 0 || *fc.Window.VideoHeight <= 0 {
+			log.Printf("config: window.video_width/video_height must be > 0 (got %dx%d); using the monitor's current mode", *fc.Window.VideoWidth, *fc.Window.VideoHeight)
+		} else {
+			cfg.window.videoWidth, cfg.window.videoHeight = *fc.Window.VideoWidth, *fc.Window.VideoHeight
+		}
+	} else if fc.Window.VideoWidth != nil || fc.Window.VideoHeight != nil {
+		log.Printf("config: window.video_width and video_height must be set together; using the monitor's current mode")
+	}
+	if fc.Window.RefreshRate != nil {
+		if *fc.Window.RefreshRate < 0 {
+			log.Printf("config: window.refresh_rate must be >= 0 (got %d); keeping %d", *fc.Window.RefreshRate, cfg.window.refreshRate)
+		} else {
+			cfg.window.refreshRate = *fc.Window.RefreshRate
+		}
+	}
+	if fc.Keybindings != nil {
+		cfg.keys.applyOverrides(fc.Keybindings)
+	}
+	if fc.Gamepad.Enabled != nil {
+		cfg.gamepad.enabled = *fc.Gamepad.Enabled
+	}
+	if fc.Gamepad.Joystick != nil {
+		if *fc.Gamepad.Joystick < 0 || *fc.Gamepad.Joystick > 16 {
+			log.Printf("config: gamepad.joystick must be 0 (first connected) or 1-16 (got %d); keeping %d", *fc.Gamepad.Joystick, cfg.gamepad.joystick)
+		} else {
+			cfg.gamepad.joystick = *fc.Gamepad.Joystick
+		}
+	}
+	if fc.Gamepad.Deadzone != nil {
+		if *fc.Gamepad.Deadzone < 0 || *fc.Gamepad.Deadzone >= 1 {
+			log.Printf("config: gamepad.deadzone must be in [0, 1) (got %g); keeping %g", *fc.Gamepad.Deadzone, cfg.gamepad.deadzone)
+		} else {
+			cfg.gamepad.deadzone = *fc.Gamepad.Deadzone
+		}
+	}
+	if fc.Gamepad.LookSpeed != nil {
+		if *fc.Gamepad.LookSpeed <= 0 {
+			log.Printf("config: gamepad.look_speed must be > 0 (got %g); keeping %g", *fc.Gamepad.LookSpeed, cfg.gamepad.lookSpeed)
+		} else {
+			cfg.gamepad.lookSpeed = *fc.Gamepad.LookSpeed
+		}
+	}
+	if fc.Gamepad.ZoomSpeed != nil {
+		if *fc.Gamepad.ZoomSpeed <= 0 {
+			log.Printf("config: gamepad.zoom_speed must be > 0 (got %g); keeping %g", *fc.Gamepad.ZoomSpeed, cfg.gamepad.zoomSpeed)
+		} else {
+			cfg.gamepad.zoomSpeed = *fc.Gamepad.ZoomSpeed
+		}
+	}
+	if fc.Gamepad.InvertY != nil {
+		cfg.gamepad.invertY = *fc.Gamepad.InvertY
+	}
+	if fc.Gamepad.Axes != nil {
+		cfg.gamepad.parseGamepadAxes(fc.Gamepad.Axes)
+	}
+	if fc.Gamepad.Buttons != nil {
+		cfg.gamepad.parseGamepadButtons(fc.Gamepad.Buttons)
+	}
+	if fc.MetricsAddr != nil && strings.TrimSpace(*fc.MetricsAddr) != "" {
+		if addr, err := metricsListenAddr(strings.TrimSpace(*fc.MetricsAddr)); err != nil {
+			log.Printf("config: metrics_addr: %v; metrics endpoint disabled", err)
+		} else {
+			cfg.metricsAddr = addr
+		}
+	}
+	if fc.StatsExport != nil {
+		cfg.statsExportPath = strings.TrimSpace(*fc.StatsExport)
+	}
+	if fc.InputRecord != nil {
+		cfg.inputRecordPath = strings.TrimSpace(*fc.InputRecord)
+	}
+	if fc.InputReplay != nil {
+		cfg.inputReplayPath = strings.TrimSpace(*fc.InputReplay)
+	}
+	if fc.Mesh != nil {
+		cfg.meshPath = strings.TrimSpace(*fc.Mesh)
+	}
+	if fc.Animation != nil {
+		cfg.animationPath = strings.TrimSpace(*fc.Animation)
+	}
+	if fc.Skybox != nil {
+		cfg.skyboxPath = strings.TrimSpace(*fc.Skybox)
+	}
+	if len(fc.SkyboxFace) > 0 {
+		if len(fc.SkyboxFace) != int(cubeFaceCount) {
+			log.Printf("config: skybox_faces needs %d entries (got %d); ignoring", cubeFaceCount, len(fc.SkyboxFace))
+		} else {
+			for _, f := range fc.SkyboxFace {
+				cfg.skyboxFacePaths = append(cfg.skyboxFacePaths, strings.TrimSpace(f))
+			}
+		}
+	}
 
 	log.Printf("config: loaded %s (validation=%v vsync=%v maxFPS=%d)", path, cfg.enableValidation, cfg.vsyncEnabled, cfg.maxFPS)
 	return cfg
 }
 
-// togglePause switches rotation on/off and tracks timing to keep animation consistent.
+// togglePause freezes or resumes the simulation clock.
 func (a *VulkanApp) togglePause() {
-	if a.paused {
-		a.startTime = time.Now()
-		a.paused = false
+	if a.clock.togglePause() {
+		log.Printf("Paused at step %d (%.3fs simulated)", a.clock.steps, a.clock.seconds())
 		return
 	}
-	a.pausedAccum += time.Since(a.startTime)
-	a.pausedAt = time.Now()
-	a.paused = true
+	log.Printf("Resumed")
+}
+
+// toggleHUD shows or hides the overlay.
+func (a *VulkanApp) toggleHUD() {
+	a.hudHidden = !a.hudHidden
+}
+
+// toggleHelp shows or hides the key binding list in the overlay.
+func (a *VulkanApp) toggleHelp() {
+	a.helpVisible = !a.helpVisible
+	if a.helpVisible {
+		a.hudHidden = false
+	}
+}
+
+// toggleVsync switches between FIFO and the low-latency present mode via a swapchain rebuild.
+func (a *VulkanApp) toggleVsync() {
+	a.cfg.vsyncEnabled = !a.cfg.vsyncEnabled
+	log.Printf("Vsync: %v", a.cfg.vsyncEnabled)
+	a.requestSwapchainRecreate()
+}
+
+// toggleFullscreen switches between windowed and fullscreen. The framebuffer size callback
+// usually fires too, but a mode switch at the same size (or on a compositor that reports it
+// late) still needs a new swapchain.
+func (a *VulkanApp) toggleFullscreen() {
+	a.display.toggle(a.window)
+	a.requestSwapchainRecreate()
+}
+
+// nextMonitor moves fullscreen output to the next monitor.
+func (a *VulkanApp) nextMonitor() {
+	a.display.nextMonitor(a.window)
+	a.requestSwapchainRecreate()
+}
+
+// stepFrame advances a paused simulation by exactly one fixed step.
+func (a *VulkanApp) stepFrame() {
+	if a.clock.singleStep() {
+		log.Printf("Single step to %d", a.clock.steps+1)
+	}
+}
+
+// scaleTime multiplies the simulation time scale; a factor of 0 resets it to real time.
+func (a *VulkanApp) scaleTime(factor float64) {
+	if factor == 0 {
+		a.clock.setScale(1)
+	} else {
+		a.clock.scaleBy(factor)
+	}
+	log.Printf("Time scale: %gx", a.clock.scale)
 }
 
-// animationSeconds returns total unpaused time in seconds for time-based animations.
-func (a *VulkanApp) animationSeconds() float32 {
-	active := a.pausedAccum
-	if !a.paused {
-		active += time.Since(a.startTime)
+// advanceSimulation runs this frame's fixed steps and returns the interpolated model matrix
+// for rendering.
+func (a *VulkanApp) advanceSimulation(steps int) mgl32.Mat4 {
+	if a.animation != nil {
+		return a.animation.model(a.clock.renderSeconds())
 	}
-	return float32(active.Seconds())
+	if a.rotation.cfg.mode == animationFrame {
+		// Frame-locked: one nominal frame per rendered frame, scaled like simulated time.
+		if a.clock.running(steps) {
+			a.rotation.step(float32(a.clock.scale) / rotationFrameRate)
+		}
+		return a.rotation.model(1)
+	}
+	for i := 0; i < steps; i++ {
+		a.rotation.step(a.clock.stepSeconds())
+	}
+	return a.rotation.model(a.clock.alpha())
 }
 
 // initVulkan runs the full Vulkan initialization sequence and logs each major milestone.
@@ -356,6 +931,9 @@ func (a *VulkanApp) initVulkan() error {
 		return err
 	}
 	log.Printf("Descriptor set layout created")
+	if err := a.createOverlaySetLayout(); err != nil {
+		return err
+	}
 	// Graphics/overlay pipelines.
 	if err := a.createGraphicsPipeline(); err != nil {
 		return err
@@ -373,6 +951,13 @@ func (a *VulkanApp) initVulkan() error {
 		return err
 	}
 	log.Printf("Command pool created")
+	if err := a.createGPUTimer(); err != nil {
+		log.Printf("GPU timing disabled: %v", err)
+	}
+	if err := a.createPipelineStatsQueries(); err != nil {
+		log.Printf("Pipeline statistics disabled: %v", err)
+	}
+	a.mesh = a.loadMesh()
 	if err := a.createVertexBuffer(); err != nil {
 		return err
 	}
@@ -385,6 +970,10 @@ func (a *VulkanApp) initVulkan() error {
 		return err
 	}
 	log.Printf("Overlay buffers created")
+	if err := a.createOverlayAtlas(); err != nil {
+		return err
+	}
+	log.Printf("Overlay glyph atlas created")
 	if err := a.createTextureImage(); err != nil {
 		return err
 	}
@@ -397,6 +986,16 @@ func (a *VulkanApp) initVulkan() error {
 		return err
 	}
 	log.Printf("Texture sampler created")
+	if err := a.createMaterials(); err != nil {
+		return err
+	}
+	log.Printf("Materials created")
+	if err := a.createSkybox(); err != nil {
+		return err
+	}
+	if a.skyboxPipeline != vulkan.Pipeline(vulkan.NullHandle) {
+		log.Printf("Skybox created")
+	}
 	if err := a.createUniformBuffers(); err != nil {
 		return err
 	}
@@ -416,7 +1015,6 @@ func (a *VulkanApp) initVulkan() error {
 	if err := a.createSyncObjects(); err != nil {
 		return err
 	}
-	a.startTime = time.Now()
 	a.fpsLastTime = time.Now()
 	log.Printf("Vulkan initialization complete (swapchain images: %d)", len(a.swapchainImages))
 	return nil
@@ -537,6 +1135,7 @@ func (a *VulkanApp) setupDebugCallback() error {
 				vulkan.DebugReportPerformanceWarningBit),
 		PfnCallback: func(flags vulkan.DebugReportFlags, objectType vulkan.DebugReportObjectType, object uint64, location uint, messageCode int32, layerPrefix string, message string, userData unsafe.Pointer) vulkan.Bool32 {
 			log.Printf("[VK][%s][0x%x] %s (code=%d)", layerPrefix, flags, message, messageCode)
+			a.metrics.validationMessage(validationSeverity(flags))
 			return vulkan.False
 		},
 	}
@@ -596,6 +1195,12 @@ func (a *VulkanApp) pickPhysicalDevice() error {
 
 	a.physicalDevice = selected
 	a.queues = selectedQueues
+	var props vulkan.PhysicalDeviceProperties
+	vulkan.GetPhysicalDeviceProperties(selected, &props)
+	props.Deref()
+	a.deviceProps = props
+	a.deviceName = vulkan.ToString(props.DeviceName[:])
+	log.Printf("GPU: %s", a.deviceName)
 	return nil
 }
 
@@ -683,7 +1288,17 @@ func (a *VulkanApp) createLogicalDevice() error {
 		})
 	}
 
-	deviceFeatures := vulkan.PhysicalDeviceFeatures{}
+	// Enable whichever block-compression families the GPU offers so KTX2 textures can stay compressed.
+	var supportedFeatures vulkan.PhysicalDeviceFeatures
+	vulkan.GetPhysicalDeviceFeatures(a.physicalDevice, &supportedFeatures)
+	supportedFeatures.Deref()
+	deviceFeatures := vulkan.PhysicalDeviceFeatures{
+		TextureCompressionBC:       supportedFeatures.TextureCompressionBC,
+		TextureCompressionETC2:     supportedFeatures.TextureCompressionETC2,
+		TextureCompressionASTC_LDR: supportedFeatures.TextureCompressionASTC_LDR,
+		PipelineStatisticsQuery:    supportedFeatures.PipelineStatisticsQuery,
+	}
+	a.pipelineStatsSupported = supportedFeatures.PipelineStatisticsQuery == vulkan.True
 	extNames, extPtrs := makeCStringSlice(deviceExtensions)
 	defer freeCStrings(extPtrs)
 
@@ -860,7 +1475,14 @@ func (a *VulkanApp) createSwapchain() error {
 
 	surfaceFormat := chooseSwapSurfaceFormat(support.formats)
 	presentMode := chooseSwapPresentMode(support.presentModes, a.cfg.vsyncEnabled)
+	a.presentMode = presentMode
 	extent := chooseSwapExtent(support.capabilities, a.window)
+	usage := vulkan.ImageUsageFlags(vulkan.ImageUsageColorAttachmentBit)
+	// Screenshots copy out of the swapchain image when the surface allows it.
+	a.swapchainCanCapture = support.capabilities.SupportedUsageFlags&vulkan.ImageUsageFlags(vulkan.ImageUsageTransferSrcBit) != 0
+	if a.swapchainCanCapture {
+		usage |= vulkan.ImageUsageFlags(vulkan.ImageUsageTransferSrcBit)
+	}
 
 	imageCount := support.capabilities.MinImageCount + 1
 	if support.capabilities.MaxImageCount > 0 && imageCount > support.capabilities.MaxImageCount {
@@ -877,7 +1499,7 @@ func (a *VulkanApp) createSwapchain() error {
 		ImageColorSpace:  surfaceFormat.ColorSpace,
 		ImageExtent:      extent,
 		ImageArrayLayers: 1,
-		ImageUsage:       vulkan.ImageUsageFlags(vulkan.ImageUsageColorAttachmentBit),
+		ImageUsage:       usage,
 		PreTransform:     support.capabilities.CurrentTransform,
 		CompositeAlpha:   vulkan.CompositeAlphaOpaqueBit,
 		PresentMode:      presentMode,
@@ -947,7 +1569,7 @@ func (a *VulkanApp) createDepthResources() error {
 	view, err := a.createImageView(image, depthFormat, vulkan.ImageAspectFlags(vulkan.ImageAspectDepthBit))
 	if err != nil {
 		vulkan.DestroyImage(a.device, image, nil)
-		vulkan.FreeMemory(a.device, memory, nil)
+		a.freeMemory(memory)
 		return fmt.Errorf("create depth image view: %w", err)
 	}
 	a.depthImage = image
@@ -981,16 +1603,22 @@ func (a *VulkanApp) findSupportedFormat(candidates []vulkan.Format, tiling vulka
 }
 
 func (a *VulkanApp) createImage(width, height uint32, format vulkan.Format, tiling vulkan.ImageTiling, usage vulkan.ImageUsageFlags, properties vulkan.MemoryPropertyFlagBits) (vulkan.Image, vulkan.DeviceMemory, error) {
+	return a.createImageLevels(width, height, 1, 1, 0, format, tiling, usage, properties)
+}
+
+// createImageLevels creates a 2D image with an explicit mip chain, layer count, and create flags.
+func (a *VulkanApp) createImageLevels(width, height, mipLevels, layers uint32, flags vulkan.ImageCreateFlags, format vulkan.Format, tiling vulkan.ImageTiling, usage vulkan.ImageUsageFlags, properties vulkan.MemoryPropertyFlagBits) (vulkan.Image, vulkan.DeviceMemory, error) {
 	createInfo := vulkan.ImageCreateInfo{
 		SType:     vulkan.StructureTypeImageCreateInfo,
+		Flags:     flags,
 		ImageType: vulkan.ImageType2d,
 		Extent: vulkan.Extent3D{
 			Width:  width,
 			Height: height,
 			Depth:  1,
 		},
-		MipLevels:     1,
-		ArrayLayers:   1,
+		MipLevels:     mipLevels,
+		ArrayLayers:   layers,
 		Format:        format,
 		Tiling:        tiling,
 		InitialLayout: vulkan.ImageLayoutUndefined,
@@ -1034,13 +1662,13 @@ func (a *VulkanApp) createImage(width, height uint32, format vulkan.Format, tili
 	}
 	defer C.free(unsafe.Pointer(memoryOut))
 
-	if res := vulkan.AllocateMemory(a.device, &allocInfo, nil, memoryOut); res != vulkan.Success {
+	if res := a.allocateMemory(&allocInfo, memoryOut); res != vulkan.Success {
 		vulkan.DestroyImage(a.device, *imageOut, nil)
 		return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), fmt.Errorf("allocate image memory: %w", vulkan.Error(res))
 	}
 
 	if res := vulkan.BindImageMemory(a.device, *imageOut, *memoryOut, 0); res != vulkan.Success {
-		vulkan.FreeMemory(a.device, *memoryOut, nil)
+		a.freeMemory(*memoryOut)
 		vulkan.DestroyImage(a.device, *imageOut, nil)
 		return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), fmt.Errorf("bind image memory: %w", vulkan.Error(res))
 	}
@@ -1064,10 +1692,15 @@ func (a *VulkanApp) findMemoryType(typeFilter uint32, properties vulkan.MemoryPr
 }
 
 func (a *VulkanApp) createImageView(image vulkan.Image, format vulkan.Format, aspectFlags vulkan.ImageAspectFlags) (vulkan.ImageView, error) {
+	return a.createImageViewLevels(image, format, aspectFlags, vulkan.ImageViewType2d, 1, 1)
+}
+
+// createImageViewLevels creates a view of the given type covering all mip levels and layers.
+func (a *VulkanApp) createImageViewLevels(image vulkan.Image, format vulkan.Format, aspectFlags vulkan.ImageAspectFlags, viewType vulkan.ImageViewType, mipLevels, layers uint32) (vulkan.ImageView, error) {
 	viewInfo := vulkan.ImageViewCreateInfo{
 		SType:    vulkan.StructureTypeImageViewCreateInfo,
 		Image:    image,
-		ViewType: vulkan.ImageViewType2d,
+		ViewType: viewType,
 		Format:   format,
 		Components: vulkan.ComponentMapping{
 			R: vulkan.ComponentSwizzleIdentity,
@@ -1078,9 +1711,9 @@ func (a *VulkanApp) createImageView(image vulkan.Image, format vulkan.Format, as
 		SubresourceRange: vulkan.ImageSubresourceRange{
 			AspectMask:     aspectFlags,
 			BaseMipLevel:   0,
-			LevelCount:     1,
+			LevelCount:     mipLevels,
 			BaseArrayLayer: 0,
-			LayerCount:     1,
+			LayerCount:     layers,
 		},
 	}
 	var zero vulkan.ImageView
@@ -1095,14 +1728,14 @@ func (a *VulkanApp) createImageView(image vulkan.Image, format vulkan.Format, as
 	return *viewOut, nil
 }
 
-func (a *VulkanApp) transitionImageLayout(image vulkan.Image, format vulkan.Format, oldLayout, newLayout vulkan.ImageLayout) error {
+func (a *VulkanApp) transitionImageLayout(image vulkan.Image, format vulkan.Format, oldLayout, newLayout vulkan.ImageLayout, mipLevels, layers uint32) error {
 	return a.oneTimeCommands(func(cb vulkan.CommandBuffer) {
 		subresource := vulkan.ImageSubresourceRange{
 			AspectMask:     vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit),
 			BaseMipLevel:   0,
-			LevelCount:     1,
+			LevelCount:     mipLevels,
 			BaseArrayLayer: 0,
-			LayerCount:     1,
+			LayerCount:     layers,
 		}
 		if newLayout == vulkan.ImageLayoutDepthStencilAttachmentOptimal {
 			subresource.AspectMask = vulkan.ImageAspectFlags(vulkan.ImageAspectDepthBit)
@@ -1144,23 +1777,10 @@ func (a *VulkanApp) transitionImageLayout(image vulkan.Image, format vulkan.Form
 	})
 }
 
-// copyBufferToImage records a buffer-to-image transfer for a linear staging upload.
-func (a *VulkanApp) copyBufferToImage(buffer vulkan.Buffer, image vulkan.Image, width, height uint32) error {
+// copyBufferToImage records buffer-to-image transfers for a staging upload, one region per level/layer.
+func (a *VulkanApp) copyBufferToImage(buffer vulkan.Buffer, image vulkan.Image, regions []vulkan.BufferImageCopy) error {
 	return a.oneTimeCommands(func(cb vulkan.CommandBuffer) {
-		region := vulkan.BufferImageCopy{
-			BufferOffset:      0,
-			BufferRowLength:   0,
-			BufferImageHeight: 0,
-			ImageSubresource: vulkan.ImageSubresourceLayers{
-				AspectMask:     vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit),
-				MipLevel:       0,
-				BaseArrayLayer: 0,
-				LayerCount:     1,
-			},
-			ImageOffset: vulkan.Offset3D{X: 0, Y: 0, Z: 0},
-			ImageExtent: vulkan.Extent3D{Width: width, Height: height, Depth: 1},
-		}
-		vulkan.CmdCopyBufferToImage(cb, buffer, image, vulkan.ImageLayoutTransferDstOptimal, 1, []vulkan.BufferImageCopy{region})
+		vulkan.CmdCopyBufferToImage(cb, buffer, image, vulkan.ImageLayoutTransferDstOptimal, uint32(len(regions)), regions)
 	})
 }
 
@@ -1258,16 +1878,13 @@ func (a *VulkanApp) createUniformBuffers() error {
 
 // createDescriptorPool builds a descriptor pool sized for the swapchain images.
 func (a *VulkanApp) createDescriptorPool() error {
-	// Pool sized for per-frame UBO + sampler descriptors; recreated on swapchain rebuild.
+	// Pool sized for per-frame UBO descriptors; recreated on swapchain rebuild.
+	// Material samplers live in their own pool (see createMaterials).
 	poolSizes := []vulkan.DescriptorPoolSize{
 		{
 			Type:            vulkan.DescriptorTypeUniformBuffer,
 			DescriptorCount: uint32(len(a.swapchainImages)),
 		},
-		{
-			Type:            vulkan.DescriptorTypeCombinedImageSampler,
-			DescriptorCount: uint32(len(a.swapchainImages)),
-		},
 	}
 	poolInfo := vulkan.DescriptorPoolCreateInfo{
 		SType:         vulkan.StructureTypeDescriptorPoolCreateInfo,
@@ -1290,7 +1907,7 @@ func (a *VulkanApp) createDescriptorPool() error {
 
 // createDescriptorSets allocates descriptor sets and writes UBO + sampler bindings.
 func (a *VulkanApp) createDescriptorSets() error {
-	// Allocate and write descriptors for each swapchain image (UBO + texture).
+	// Allocate and write the UBO descriptor for each swapchain image.
 	layouts := make([]vulkan.DescriptorSetLayout, len(a.swapchainImages))
 	for i := range layouts {
 		layouts[i] = a.descriptorSetLayout
@@ -1331,11 +1948,6 @@ func (a *VulkanApp) createDescriptorSets() error {
 			Offset: 0,
 			Range:  vulkan.DeviceSize(unsafe.Sizeof(uniformBufferObject{})),
 		}
-		imageInfo := vulkan.DescriptorImageInfo{
-			ImageLayout: vulkan.ImageLayoutShaderReadOnlyOptimal,
-			ImageView:   a.textureImageView,
-			Sampler:     a.textureSampler,
-		}
 		write := vulkan.WriteDescriptorSet{
 			SType:           vulkan.StructureTypeWriteDescriptorSet,
 			DstSet:          a.descriptorSets[i],
@@ -1345,35 +1957,20 @@ func (a *VulkanApp) createDescriptorSets() error {
 			DescriptorCount: 1,
 			PBufferInfo:     []vulkan.DescriptorBufferInfo{bufferInfo},
 		}
-		writeSampler := vulkan.WriteDescriptorSet{
-			SType:           vulkan.StructureTypeWriteDescriptorSet,
-			DstSet:          a.descriptorSets[i],
-			DstBinding:      1,
-			DstArrayElement: 0,
-			DescriptorType:  vulkan.DescriptorTypeCombinedImageSampler,
-			DescriptorCount: 1,
-			PImageInfo:      []vulkan.DescriptorImageInfo{imageInfo},
-		}
-		vulkan.UpdateDescriptorSets(a.device, 2, []vulkan.WriteDescriptorSet{write, writeSampler}, 0, nil)
+		vulkan.UpdateDescriptorSets(a.device, 1, []vulkan.WriteDescriptorSet{write}, 0, nil)
 	}
 	return nil
 }
 
 // updateUniformBuffer writes the model/view/projection matrices into the UBO for a frame.
 func (a *VulkanApp) updateUniformBuffer(imageIndex uint32) error {
-	elapsed := float32(a.debugFrames)
-	degPerFrame := 45.0 / 60.0
-	spinZ := elapsed * mgl32.DegToRad(float32(degPerFrame))
-	//spinY := elapsed * mgl32.DegToRad(30)
-	model := mgl32.HomogRotate3D(spinZ, mgl32.Vec3{0, 0, 1})
-	//model = mgl32.HomogRotate3D(spinY, mgl32.Vec3{0, 1, 0}).Mul4(model)
-	view := mgl32.LookAtV(
-		mgl32.Vec3{3, 3, 3},
-		mgl32.Vec3{0, 0, 0},
-		mgl32.Vec3{0, 0, 1},
-	)
-	proj := mgl32.Perspective(mgl32.DegToRad(45), float32(a.swapchainExtent.Width)/float32(a.swapchainExtent.Height), 0.1, 10.0)
-	proj[5] *= -1 // Vulkan clip
+	steps, cameraDt := a.frameTiming()
+	model := a.advanceSimulation(steps)
+	a.camera.update(cameraDt)
+	view := a.camera.view()
+	proj := a.camera.projection(float32(a.swapchainExtent.Width) / float32(a.swapchainExtent.Height))
+	// The skybox ignores camera translation so it stays infinitely far away.
+	a.skyboxViewProj = proj.Mul4(view.Mat3().Mat4())
 
 	ubo := uniformBufferObject{
 		Model: model,
@@ -1393,9 +1990,9 @@ func (a *VulkanApp) updateUniformBuffer(imageIndex uint32) error {
 	return nil
 }
 
-// createVertexBuffer uploads cube vertices into a host-visible vertex buffer.
+// createVertexBuffer uploads the mesh vertices into a host-visible vertex buffer.
 func (a *VulkanApp) createVertexBuffer() error {
-	bufferSize := vulkan.DeviceSize(len(cubeVertices)) * vulkan.DeviceSize(unsafe.Sizeof(vertex{}))
+	bufferSize := vulkan.DeviceSize(len(a.mesh.vertices)) * vulkan.DeviceSize(unsafe.Sizeof(vertex{}))
 	buf, mem, err := a.createBuffer(bufferSize, vulkan.BufferUsageFlags(vulkan.BufferUsageVertexBufferBit), vulkan.MemoryPropertyHostVisibleBit|vulkan.MemoryPropertyHostCoherentBit)
 	if err != nil {
 		return err
@@ -1408,14 +2005,15 @@ func (a *VulkanApp) createVertexBuffer() error {
 		return fmt.Errorf("map vertex buffer: %w", vulkan.Error(res))
 	}
 	dst := (*[1 << 30]byte)(data)[:bufferSize:bufferSize]
-	copy(dst, verticesToBytes(cubeVertices))
+	copy(dst, verticesToBytes(a.mesh.vertices))
 	vulkan.UnmapMemory(a.device, mem)
 	return nil
 }
 
-// createIndexBuffer uploads cube indices into a host-visible index buffer.
+// createIndexBuffer uploads the mesh indices into a host-visible index buffer, 16-bit when they fit.
 func (a *VulkanApp) createIndexBuffer() error {
-	bufferSize := vulkan.DeviceSize(len(cubeIndices) * 4)
+	indexData := a.mesh.indexBytes()
+	bufferSize := vulkan.DeviceSize(len(indexData))
 	buf, mem, err := a.createBuffer(bufferSize, vulkan.BufferUsageFlags(vulkan.BufferUsageIndexBufferBit), vulkan.MemoryPropertyHostVisibleBit|vulkan.MemoryPropertyHostCoherentBit)
 	if err != nil {
 		return err
@@ -1428,8 +2026,9 @@ func (a *VulkanApp) createIndexBuffer() error {
 		return fmt.Errorf("map index buffer: %w", vulkan.Error(res))
 	}
 	dst := (*[1 << 30]byte)(data)[:bufferSize:bufferSize]
-	copy(dst, indicesToBytes(cubeIndices))
+	copy(dst, indexData)
 	vulkan.UnmapMemory(a.device, mem)
+	a.indexType = a.mesh.indexType()
 	return nil
 }
 
@@ -1451,7 +2050,7 @@ func indicesToBytes(idxs []uint32) []byte {
 	return out
 }
 
-// createDescriptorSetLayout defines the bindings for UBO and combined image sampler.
+// createDescriptorSetLayout defines set 0 (per-frame UBO) and set 1 (material sampler).
 func (a *VulkanApp) createDescriptorSetLayout() error {
 	uLayoutBinding := vulkan.DescriptorSetLayoutBinding{
 		Binding:         0,
@@ -1459,16 +2058,10 @@ func (a *VulkanApp) createDescriptorSetLayout() error {
 		DescriptorCount: 1,
 		StageFlags:      vulkan.ShaderStageFlags(vulkan.ShaderStageVertexBit),
 	}
-	samplerBinding := vulkan.DescriptorSetLayoutBinding{
-		Binding:         1,
-		DescriptorType:  vulkan.DescriptorTypeCombinedImageSampler,
-		DescriptorCount: 1,
-		StageFlags:      vulkan.ShaderStageFlags(vulkan.ShaderStageFragmentBit),
-	}
 	layoutInfo := vulkan.DescriptorSetLayoutCreateInfo{
 		SType:        vulkan.StructureTypeDescriptorSetLayoutCreateInfo,
-		BindingCount: 2,
-		PBindings:    []vulkan.DescriptorSetLayoutBinding{uLayoutBinding, samplerBinding},
+		BindingCount: 1,
+		PBindings:    []vulkan.DescriptorSetLayoutBinding{uLayoutBinding},
 	}
 	var zero vulkan.DescriptorSetLayout
 	out := (*vulkan.DescriptorSetLayout)(C.malloc(C.size_t(unsafe.Sizeof(zero))))
@@ -1480,7 +2073,7 @@ func (a *VulkanApp) createDescriptorSetLayout() error {
 		return fmt.Errorf("create descriptor set layout: %w", vulkan.Error(res))
 	}
 	a.descriptorSetLayout = *out
-	return nil
+	return a.createMaterialSetLayout()
 }
 
 func (a *VulkanApp) createGraphicsPipeline() error {
@@ -1531,6 +2124,7 @@ func (a *VulkanApp) createGraphicsPipeline() error {
 		{Location: 0, Binding: 0, Format: vulkan.FormatR32g32b32Sfloat, Offset: uint32(unsafe.Offsetof(vertex{}.pos))},
 		{Location: 1, Binding: 0, Format: vulkan.FormatR32g32b32Sfloat, Offset: uint32(unsafe.Offsetof(vertex{}.color))},
 		{Location: 2, Binding: 0, Format: vulkan.FormatR32g32Sfloat, Offset: uint32(unsafe.Offsetof(vertex{}.uv))},
+		{Location: 3, Binding: 0, Format: vulkan.FormatR32Uint, Offset: uint32(unsafe.Offsetof(vertex{}.face))},
 	}
 
 	vertexInput := vulkan.PipelineVertexInputStateCreateInfo{
@@ -1605,9 +2199,14 @@ func (a *VulkanApp) createGraphicsPipeline() error {
 
 	pipelineLayoutInfo := vulkan.PipelineLayoutCreateInfo{
 		SType:                  vulkan.StructureTypePipelineLayoutCreateInfo,
-		SetLayoutCount:         1,
-		PSetLayouts:            []vulkan.DescriptorSetLayout{a.descriptorSetLayout},
-		PushConstantRangeCount: 0,
+		SetLayoutCount:         2,
+		PSetLayouts:            []vulkan.DescriptorSetLayout{a.descriptorSetLayout, a.materialSetLayout},
+		PushConstantRangeCount: 1,
+		PPushConstantRanges: []vulkan.PushConstantRange{{
+			StageFlags: vulkan.ShaderStageFlags(vulkan.ShaderStageVertexBit | vulkan.ShaderStageFragmentBit),
+			Offset:     0,
+			Size:       uint32(unsafe.Sizeof(drawPushConstants{})),
+		}},
 	}
 	var zeroLayout vulkan.PipelineLayout
 	layoutOut := (*vulkan.PipelineLayout)(C.malloc(C.size_t(unsafe.Sizeof(zeroLayout))))
@@ -1724,7 +2323,7 @@ func (a *VulkanApp) createBuffer(size vulkan.DeviceSize, usage vulkan.BufferUsag
 	}
 	defer C.free(unsafe.Pointer(bufferMemoryOut))
 
-	if res := vulkan.AllocateMemory(a.device, &allocInfo, nil, bufferMemoryOut); res != vulkan.Success {
+	if res := a.allocateMemory(&allocInfo, bufferMemoryOut); res != vulkan.Success {
 		vulkan.DestroyBuffer(a.device, *bufferOut, nil)
 		return vulkan.Buffer(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), fmt.Errorf("allocate buffer memory: %w", vulkan.Error(res))
 	}
@@ -2015,6 +2614,7 @@ func (a *VulkanApp) cleanupSwapchain() {
 		vulkan.DestroyPipelineLayout(a.device, a.overlayPipelineLayout, nil)
 		a.overlayPipelineLayout = vulkan.PipelineLayout(vulkan.NullHandle)
 	}
+	a.destroySkyboxPipeline()
 	for _, view := range a.swapchainViews {
 		vulkan.DestroyImageView(a.device, view, nil)
 	}
@@ -2028,7 +2628,7 @@ func (a *VulkanApp) cleanupSwapchain() {
 		a.depthImage = vulkan.Image(vulkan.NullHandle)
 	}
 	if a.depthImageMemory != vulkan.DeviceMemory(vulkan.NullHandle) {
-		vulkan.FreeMemory(a.device, a.depthImageMemory, nil)
+		a.freeMemory(a.depthImageMemory)
 		a.depthImageMemory = vulkan.DeviceMemory(vulkan.NullHandle)
 	}
 	for i := range a.uniformBuffers {
@@ -2036,7 +2636,7 @@ func (a *VulkanApp) cleanupSwapchain() {
 			vulkan.DestroyBuffer(a.device, a.uniformBuffers[i], nil)
 		}
 		if a.uniformBuffersMemory[i] != vulkan.DeviceMemory(vulkan.NullHandle) {
-			vulkan.FreeMemory(a.device, a.uniformBuffersMemory[i], nil)
+			a.freeMemory(a.uniformBuffersMemory[i])
 		}
 	}
 	a.uniformBuffers = nil
@@ -2054,6 +2654,9 @@ func (a *VulkanApp) cleanupSwapchain() {
 // recreateSwapchain rebuilds swapchain-dependent resources after resize/out-of-date.
 func (a *VulkanApp) recreateSwapchain() error {
 	vulkan.DeviceWaitIdle(a.device)
+	a.swapchainRecreates++
+	a.metrics.swapchainRecreated()
+	a.frameStamps.recreated = true
 	a.cleanupSwapchain()
 
 	if err := a.createSwapchain(); err != nil {
@@ -2074,6 +2677,9 @@ func (a *VulkanApp) recreateSwapchain() error {
 	if err := a.createOverlayPipeline(); err != nil {
 		return err
 	}
+	if err := a.createSkyboxPipeline(); err != nil {
+		return err
+	}
 	if err := a.createFramebuffers(); err != nil {
 		return err
 	}
@@ -2102,6 +2708,8 @@ func (a *VulkanApp) recordCommandBuffer(cb vulkan.CommandBuffer, imageIndex int)
 	if res := vulkan.BeginCommandBuffer(cb, &beginInfo); res != vulkan.Success {
 		return fmt.Errorf("begin command buffer: %w", vulkan.Error(res))
 	}
+	a.beginGPUTimer(cb)
+	a.resetPipelineStats(cb)
 
 	clearColor := vulkan.NewClearValue([]float32{0.05, 0.05, 0.05, 1.0})
 	clearDepth := vulkan.NewClearDepthStencil(1.0, 0)
@@ -2125,19 +2733,28 @@ func (a *VulkanApp) recordCommandBuffer(cb vulkan.CommandBuffer, imageIndex int)
 	vertexBuffers := []vulkan.Buffer{a.vertexBuffer}
 	offsets := []vulkan.DeviceSize{0}
 	vulkan.CmdBindVertexBuffers(cb, 0, 1, vertexBuffers, offsets)
-	vulkan.CmdBindIndexBuffer(cb, a.indexBuffer, 0, vulkan.IndexTypeUint32)
+	vulkan.CmdBindIndexBuffer(cb, a.indexBuffer, 0, a.indexType)
 	vulkan.CmdBindDescriptorSets(cb, vulkan.PipelineBindPointGraphics, a.pipelineLayout, 0, 1, []vulkan.DescriptorSet{a.descriptorSets[imageIndex]}, 0, nil)
-	vulkan.CmdDrawIndexed(cb, uint32(len(cubeIndices)), 1, 0, 0, 0)
+	a.beginPipelineStats(cb)
+	a.recordMeshDraws(cb)
+	a.endPipelineStats(cb)
+	a.markGPUTimer(cb, gpuStampScene)
 
-	// Overlay FPS text.
-	if a.overlayPipeline != vulkan.Pipeline(vulkan.NullHandle) && a.overlayVertexBuffer != vulkan.Buffer(vulkan.NullHandle) {
+	// Skybox fills whatever the cube left uncovered.
+	a.recordSkybox(cb)
+	a.markGPUTimer(cb, gpuStampSkybox)
+
+	// HUD text on top of everything.
+	if !a.hudHidden && a.overlayPipeline != vulkan.Pipeline(vulkan.NullHandle) && a.overlayVertexBuffer != vulkan.Buffer(vulkan.NullHandle) {
 		vulkan.CmdBindPipeline(cb, vulkan.PipelineBindPointGraphics, a.overlayPipeline)
 		ovb := []vulkan.Buffer{a.overlayVertexBuffer}
 		voff := []vulkan.DeviceSize{0}
 		vulkan.CmdBindVertexBuffers(cb, 0, 1, ovb, voff)
+		vulkan.CmdBindDescriptorSets(cb, vulkan.PipelineBindPointGraphics, a.overlayPipelineLayout, 0, 1, []vulkan.DescriptorSet{a.overlayDescriptorSet}, 0, nil)
 		stride := vulkan.DeviceSize(unsafe.Sizeof(vulkan.DrawIndirectCommand{}))
 		vulkan.CmdDrawIndirect(cb, a.overlayIndirectBuffer, 0, 1, uint32(stride))
 	}
+	a.markGPUTimer(cb, gpuStampOverlay)
 
 	vulkan.CmdEndRenderPass(cb)
 
@@ -2150,10 +2767,12 @@ func (a *VulkanApp) recordCommandBuffer(cb vulkan.CommandBuffer, imageIndex int)
 // DrawFrame acquires, records, submits, and presents a frame with swapchain-aware sync.
 func (a *VulkanApp) DrawFrame() error {
 	frame := a.currentFrame % maxFramesInFlight
-	if a.debugFrames == 0 {
+	if a.clock.frames == 0 {
 		log.Printf("DrawFrame start (frame %d)", frame)
 	}
 	vulkan.WaitForFences(a.device, 1, []vulkan.Fence{a.inFlightFences[frame]}, vulkan.True, vulkan.MaxUint64)
+	a.readGPUTimer(frame)
+	a.readPipelineStats(frame)
 
 	if a.framebufferResized {
 		if err := a.recreateSwapchain(); err != nil {
@@ -2164,6 +2783,8 @@ func (a *VulkanApp) DrawFrame() error {
 	// Acquire the next swapchain image.
 	var imageIndex uint32
 	res := vulkan.AcquireNextImage(a.device, a.swapchain, vulkan.MaxUint64, a.imageAvailable[frame], vulkan.Fence(vulkan.NullHandle), &imageIndex)
+	a.frameStamps.acquired = time.Now()
+	a.frameStamps.imageIndex = int(imageIndex)
 	if res == vulkan.ErrorOutOfDate {
 		return a.recreateSwapchain()
 	}
@@ -2174,7 +2795,7 @@ func (a *VulkanApp) DrawFrame() error {
 	if err := a.updateUniformBuffer(imageIndex); err != nil {
 		return err
 	}
-	if err := a.updateFPSOverlay(); err != nil {
+	if err := a.updateOverlay(); err != nil {
 		return err
 	}
 
@@ -2190,6 +2811,7 @@ func (a *VulkanApp) DrawFrame() error {
 	if err := a.recordCommandBuffer(a.commandBuffers[imageIndex], int(imageIndex)); err != nil {
 		return err
 	}
+	a.frameStamps.recorded = time.Now()
 
 	// Submit work and present the image.
 	waitStages := []vulkan.PipelineStageFlags{vulkan.PipelineStageFlags(vulkan.PipelineStageColorAttachmentOutputBit)}
@@ -2206,6 +2828,13 @@ func (a *VulkanApp) DrawFrame() error {
 	if res := vulkan.QueueSubmit(a.graphicsQueue, 1, []vulkan.SubmitInfo{submitInfo}, a.inFlightFences[frame]); res != vulkan.Success {
 		return fmt.Errorf("queue submit: %w", vulkan.Error(res))
 	}
+	a.frameStamps.submitted = time.Now()
+	if a.screenshotPending {
+		a.screenshotPending = false
+		if err := a.captureSwapchainImage(imageIndex); err != nil {
+			log.Printf("Screenshot: %v", err)
+		}
+	}
 
 	presentInfo := vulkan.PresentInfo{
 		SType:              vulkan.StructureTypePresentInfo,
@@ -2217,6 +2846,18 @@ func (a *VulkanApp) DrawFrame() error {
 	}
 
 	res = vulkan.QueuePresent(a.presentQueue, &presentInfo)
+	if res == vulkan.Success || res == vulkan.Suboptimal {
+		// Counted before any swapchain rebuild below, which still leaves this frame on screen.
+		a.frameStamps.presented = time.Now()
+		a.metrics.frameRendered(a.frameTime, a.frameStamps.presented)
+	}
+	if a.clock.frames < 5 {
+		log.Printf("frame %d presented (image %d, res=%v)", a.clock.frames, imageIndex, res)
+	}
+	// This frame's simulation tick is spent even if the swapchain has to be rebuilt, so it
+	// counts as a frame either way; recordings number their ticks by it.
+	a.clock.endFrame()
+
 	if res == vulkan.ErrorOutOfDate || res == vulkan.Suboptimal || a.framebufferResized {
 		return a.recreateSwapchain()
 	}
@@ -2224,13 +2865,6 @@ func (a *VulkanApp) DrawFrame() error {
 		return fmt.Errorf("queue present: %w", vulkan.Error(res))
 	}
 
-	if a.debugFrames < 5 {
-		log.Printf("frame %d presented (image %d, res=%v)", a.debugFrames, imageIndex, res)
-	}
-	if !a.paused {
-		a.debugFrames++
-	}
-
 	a.currentFrame = (a.currentFrame + 1) % maxFramesInFlight
 	return nil
 }
@@ -2238,6 +2872,9 @@ func (a *VulkanApp) DrawFrame() error {
 // Cleanup releases all Vulkan resources and the instance/surface.
 func (a *VulkanApp) Cleanup() {
 	vulkan.DeviceWaitIdle(a.device)
+	a.stopRecording()
+	a.stopFrameStats()
+	a.stopMetrics()
 
 	a.cleanupSwapchain()
 
@@ -2250,6 +2887,11 @@ func (a *VulkanApp) Cleanup() {
 	if a.commandPool != vulkan.CommandPool(vulkan.NullHandle) {
 		vulkan.DestroyCommandPool(a.device, a.commandPool, nil)
 	}
+	a.destroySkybox()
+	a.destroyOverlayAtlas()
+	a.destroyGPUTimer()
+	a.destroyPipelineStatsQueries()
+	a.destroyMaterials()
 	if a.textureSampler != vulkan.Sampler(vulkan.NullHandle) {
 		vulkan.DestroySampler(a.device, a.textureSampler, nil)
 	}
@@ -2260,31 +2902,31 @@ func (a *VulkanApp) Cleanup() {
 		vulkan.DestroyImage(a.device, a.textureImage, nil)
 	}
 	if a.textureImageMemory != vulkan.DeviceMemory(vulkan.NullHandle) {
-		vulkan.FreeMemory(a.device, a.textureImageMemory, nil)
+		a.freeMemory(a.textureImageMemory)
 	}
 	if a.vertexBuffer != vulkan.Buffer(vulkan.NullHandle) {
 		vulkan.DestroyBuffer(a.device, a.vertexBuffer, nil)
 	}
 	if a.vertexBufferMemory != vulkan.DeviceMemory(vulkan.NullHandle) {
-		vulkan.FreeMemory(a.device, a.vertexBufferMemory, nil)
+		a.freeMemory(a.vertexBufferMemory)
 	}
 	if a.indexBuffer != vulkan.Buffer(vulkan.NullHandle) {
 		vulkan.DestroyBuffer(a.device, a.indexBuffer, nil)
 	}
 	if a.indexBufferMemory != vulkan.DeviceMemory(vulkan.NullHandle) {
-		vulkan.FreeMemory(a.device, a.indexBufferMemory, nil)
+		a.freeMemory(a.indexBufferMemory)
 	}
 	if a.overlayVertexBuffer != vulkan.Buffer(vulkan.NullHandle) {
 		vulkan.DestroyBuffer(a.device, a.overlayVertexBuffer, nil)
 	}
 	if a.overlayVertexBufferMemory != vulkan.DeviceMemory(vulkan.NullHandle) {
-		vulkan.FreeMemory(a.device, a.overlayVertexBufferMemory, nil)
+		a.freeMemory(a.overlayVertexBufferMemory)
 	}
 	if a.overlayIndirectBuffer != vulkan.Buffer(vulkan.NullHandle) {
 		vulkan.DestroyBuffer(a.device, a.overlayIndirectBuffer, nil)
 	}
 	if a.overlayIndirectMemory != vulkan.DeviceMemory(vulkan.NullHandle) {
-		vulkan.FreeMemory(a.device, a.overlayIndirectMemory, nil)
+		a.freeMemory(a.overlayIndirectMemory)
 	}
 	if a.descriptorSetLayout != vulkan.DescriptorSetLayout(vulkan.NullHandle) {
 		vulkan.DestroyDescriptorSetLayout(a.device, a.descriptorSetLayout, nil)
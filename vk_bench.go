@@ -0,0 +1,65 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/vulkan-go/vulkan"
+)
+
+// prepareBench switches the config to a benchmark run: vsync, the FPS cap, and validation
+// are off so frame times reflect the renderer, and saved state and input recording are
+// skipped so runs start alike. output overrides bench.output when set.
+func (cfg *appConfig) prepareBench(output string) {
+	cfg.bench.enabled = true
+	if output != "" {
+		cfg.bench.output = output
+	}
+	cfg.vsyncEnabled = false
+	cfg.maxFPS = 0
+	cfg.enableValidation = false
+	cfg.rememberState = false
+	cfg.inputRecordPath = ""
+	log.Printf("bench: %d warmup + %d measured frames, vsync and validation off", cfg.bench.warmup, cfg.bench.frames)
+}
+
+// benchDevice describes the GPU and swapchain for the report.
+func (a *VulkanApp) benchDevice() benchDevice {
+	p := a.deviceProps
+	return benchDevice{
+		Name:          a.deviceName,
+		VendorID:      fmt.Sprintf("0x%04x", p.VendorID),
+		DeviceID:      fmt.Sprintf("0x%04x", p.DeviceID),
+		DriverVersion: formatDriverVersion(p.VendorID, p.DriverVersion),
+		APIVersion:    formatVulkanVersion(p.ApiVersion),
+		PresentMode:   presentModeName(a.presentMode),
+		Width:         a.swapchainExtent.Width,
+		Height:        a.swapchainExtent.Height,
+	}
+}
+
+// finishBench writes the benchmark report. A run cut short by closing the window still
+// reports the frames it measured.
+func (a *VulkanApp) finishBench() {
+	if a.bench.frames < a.cfg.bench.warmup+a.cfg.bench.frames {
+		log.Printf("bench: stopped after %d of %d frames", a.bench.frames, a.cfg.bench.warmup+a.cfg.bench.frames)
+	}
+	if a.gpuQueryPool == vulkan.QueryPool(vulkan.NullHandle) {
+		log.Printf("bench: GPU timestamps unavailable; reporting CPU times only")
+	} else {
+		// The last frames in flight haven't been read back yet.
+		vulkan.DeviceWaitIdle(a.device)
+		for frame := 0; frame < maxFramesInFlight; frame++ {
+			a.readGPUTimer(frame)
+		}
+	}
+	report := a.bench.report(a.benchDevice())
+	if err := report.write(a.cfg.bench.output); err != nil {
+		log.Printf("bench: %v", err)
+		return
+	}
+	report.logSummary(a.cfg.bench.output)
+}
@@ -0,0 +1,616 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+)
+
+const (
+	glbMagic     = 0x46546C67 // "glTF"
+	glbChunkJSON = 0x4E4F534A // "JSON"
+	glbChunkBIN  = 0x004E4942 // "BIN\0"
+
+	gltfModeTriangles = 4
+)
+
+// gltfDocument is the subset of the glTF 2.0 JSON schema Kube renders.
+type gltfDocument struct {
+	Scene  *int `json:"scene"`
+	Scenes []struct {
+		Nodes []int `json:"nodes"`
+	} `json:"scenes"`
+	Nodes  []gltfNode `json:"nodes"`
+	Meshes []struct {
+		Primitives []gltfPrimitive `json:"primitives"`
+	} `json:"meshes"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Buffers     []struct {
+		URI        string `json:"uri"`
+		ByteLength int    `json:"byteLength"`
+	} `json:"buffers"`
+	Materials []gltfMaterial `json:"materials"`
+	Textures  []struct {
+		Source *int `json:"source"`
+	} `json:"textures"`
+	Images []struct {
+		URI        string `json:"uri"`
+		BufferView *int   `json:"bufferView"`
+	} `json:"images"`
+}
+
+type gltfNode struct {
+	Children    []int     `json:"children"`
+	Mesh        *int      `json:"mesh"`
+	Matrix      []float32 `json:"matrix"`
+	Translation []float32 `json:"translation"`
+	Rotation    []float32 `json:"rotation"`
+	Scale       []float32 `json:"scale"`
+}
+
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    *int           `json:"indices"`
+	Material   *int           `json:"material"`
+	Mode       *int           `json:"mode"`
+}
+
+type gltfAccessor struct {
+	BufferView    *int            `json:"bufferView"`
+	ByteOffset    int             `json:"byteOffset"`
+	ComponentType int             `json:"componentType"`
+	Normalized    bool            `json:"normalized"`
+	Count         int             `json:"count"`
+	Type          string          `json:"type"`
+	Sparse        json.RawMessage `json:"sparse"`
+}
+
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	ByteStride int `json:"byteStride"`
+}
+
+type gltfMaterial struct {
+	PBR struct {
+		BaseColorFactor  []float32 `json:"baseColorFactor"`
+		BaseColorTexture *struct {
+			Index int `json:"index"`
+		} `json:"baseColorTexture"`
+	} `json:"pbrMetallicRoughness"`
+}
+
+// gltfLoader carries the parsed document and resolved buffers while building a mesh.
+type gltfLoader struct {
+	dir     string
+	doc     gltfDocument
+	buffers [][]byte
+	out     mesh
+	images  map[int]int // glTF image index -> mesh texture index
+	white   int         // mesh texture index of the 1x1 white fallback, or -1
+}
+
+// loadGLTF reads a .gltf or .glb file into a mesh with one draw per triangle primitive.
+// Node transforms become per-draw model matrices, converted from glTF's Y-up to Kube's Z-up
+// and scaled so the whole scene fits the cube's framing.
+func loadGLTF(path string) (mesh, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return mesh{}, fmt.Errorf("read gltf: %w", err)
+	}
+	l := &gltfLoader{dir: filepath.Dir(path), images: map[int]int{}, white: -1}
+
+	var bin []byte
+	if len(data) >= 12 && binary.LittleEndian.Uint32(data) == glbMagic {
+		var jsonChunk []byte
+		jsonChunk, bin, err = splitGLB(data)
+		if err != nil {
+			return mesh{}, err
+		}
+		data = jsonChunk
+	}
+	if err := json.Unmarshal(data, &l.doc); err != nil {
+		return mesh{}, fmt.Errorf("parse gltf: %w", err)
+	}
+	if err := l.loadBuffers(bin); err != nil {
+		return mesh{}, err
+	}
+
+	roots := l.sceneRoots()
+	// glTF is Y-up like OBJ.
+	for _, n := range roots {
+		if err := l.visitNode(n, yUpToZUp, 0); err != nil {
+			return mesh{}, err
+		}
+	}
+	if len(l.out.draws) == 0 {
+		return mesh{}, fmt.Errorf("%s: no triangle primitives in scene", path)
+	}
+	l.fitToCube()
+	return l.out, nil
+}
+
+// splitGLB returns the JSON and BIN chunks of a binary glTF container.
+func splitGLB(data []byte) ([]byte, []byte, error) {
+	le := binary.LittleEndian
+	if version := le.Uint32(data[4:]); version != 2 {
+		return nil, nil, fmt.Errorf("unsupported glb version %d", version)
+	}
+	total := int(le.Uint32(data[8:]))
+	if total > len(data) {
+		return nil, nil, fmt.Errorf("glb truncated: header says %d bytes, have %d", total, len(data))
+	}
+	var jsonChunk, bin []byte
+	for off := 12; off+8 <= total; {
+		length := int(le.Uint32(data[off:]))
+		kind := le.Uint32(data[off+4:])
+		start := off + 8
+		if start+length > total {
+			return nil, nil, fmt.Errorf("glb chunk at %d overruns file", off)
+		}
+		switch kind {
+		case glbChunkJSON:
+			jsonChunk = data[start : start+length]
+		case glbChunkBIN:
+			bin = data[start : start+length]
+		}
+		off = start + length
+	}
+	if jsonChunk == nil {
+		return nil, nil, fmt.Errorf("glb has no JSON chunk")
+	}
+	return jsonChunk, bin, nil
+}
+
+// loadBuffers resolves every buffer from the GLB BIN chunk, a data URI, or a sibling file.
+func (l *gltfLoader) loadBuffers(bin []byte) error {
+	for i, b := range l.doc.Buffers {
+		var data []byte
+		var err error
+		switch {
+		case b.URI == "":
+			if bin == nil {
+				return fmt.Errorf("buffer %d has no uri and no glb BIN chunk", i)
+			}
+			data = bin
+		default:
+			data, err = l.readURI(b.URI)
+			if err != nil {
+				return fmt.Errorf("buffer %d: %w", i, err)
+			}
+		}
+		if len(data) < b.ByteLength {
+			return fmt.Errorf("buffer %d: got %d bytes, want %d", i, len(data), b.ByteLength)
+		}
+		l.buffers = append(l.buffers, data)
+	}
+	return nil
+}
+
+// readURI loads a base64 data URI or a file path relative to the glTF document.
+func (l *gltfLoader) readURI(uri string) ([]byte, error) {
+	if strings.HasPrefix(uri, "data:") {
+		comma := strings.IndexByte(uri, ',')
+		if comma < 0 || !strings.HasSuffix(uri[:comma], ";base64") {
+			return nil, fmt.Errorf("unsupported data uri")
+		}
+		return base64.StdEncoding.DecodeString(uri[comma+1:])
+	}
+	name, err := url.PathUnescape(uri)
+	if err != nil {
+		return nil, fmt.Errorf("bad uri %q: %w", uri, err)
+	}
+	return os.ReadFile(filepath.Join(l.dir, filepath.FromSlash(name)))
+}
+
+// sceneRoots returns the root nodes of the default scene, or of every parentless node when
+// the file declares no scenes.
+func (l *gltfLoader) sceneRoots() []int {
+	if len(l.doc.Scenes) > 0 {
+		scene := 0
+		if l.doc.Scene != nil && *l.doc.Scene < len(l.doc.Scenes) {
+			scene = *l.doc.Scene
+		}
+		return l.doc.Scenes[scene].Nodes
+	}
+	isChild := make([]bool, len(l.doc.Nodes))
+	for _, n := range l.doc.Nodes {
+		for _, c := range n.Children {
+			if c < len(isChild) {
+				isChild[c] = true
+			}
+		}
+	}
+	var roots []int
+	for i, child := range isChild {
+		if !child {
+			roots = append(roots, i)
+		}
+	}
+	return roots
+}
+
+// visitNode accumulates node transforms depth-first and emits draws for attached meshes.
+func (l *gltfLoader) visitNode(index int, parent mgl32.Mat4, depth int) error {
+	if index < 0 || index >= len(l.doc.Nodes) {
+		return fmt.Errorf("node %d out of range", index)
+	}
+	if depth > len(l.doc.Nodes) {
+		return fmt.Errorf("node hierarchy has a cycle at node %d", index)
+	}
+	node := l.doc.Nodes[index]
+	world := parent.Mul4(node.localMatrix())
+	if node.Mesh != nil {
+		if *node.Mesh >= len(l.doc.Meshes) {
+			return fmt.Errorf("node %d: mesh %d out of range", index, *node.Mesh)
+		}
+		for p, prim := range l.doc.Meshes[*node.Mesh].Primitives {
+			if err := l.addPrimitive(prim, world); err != nil {
+				return fmt.Errorf("mesh %d primitive %d: %w", *node.Mesh, p, err)
+			}
+		}
+	}
+	for _, c := range node.Children {
+		if err := l.visitNode(c, world, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// localMatrix returns the node's matrix, or T*R*S when it uses separate components.
+func (n gltfNode) localMatrix() mgl32.Mat4 {
+	if len(n.Matrix) == 16 {
+		var m mgl32.Mat4
+		copy(m[:], n.Matrix) // both glTF and mgl32 are column-major
+		return m
+	}
+	m := mgl32.Ident4()
+	if len(n.Translation) == 3 {
+		m = m.Mul4(mgl32.Translate3D(n.Translation[0], n.Translation[1], n.Translation[2]))
+	}
+	if len(n.Rotation) == 4 {
+		q := mgl32.Quat{W: n.Rotation[3], V: mgl32.Vec3{n.Rotation[0], n.Rotation[1], n.Rotation[2]}}
+		m = m.Mul4(q.Normalize().Mat4())
+	}
+	if len(n.Scale) == 3 {
+		m = m.Mul4(mgl32.Scale3D(n.Scale[0], n.Scale[1], n.Scale[2]))
+	}
+	return m
+}
+
+// addPrimitive appends a triangle primitive's vertices and indices and records its draw.
+func (l *gltfLoader) addPrimitive(prim gltfPrimitive, model mgl32.Mat4) error {
+	if prim.Mode != nil && *prim.Mode != gltfModeTriangles {
+		log.Printf("gltf: skipping primitive with mode %d (only triangles are drawn)", *prim.Mode)
+		return nil
+	}
+	posIndex, ok := prim.Attributes["POSITION"]
+	if !ok {
+		return fmt.Errorf("missing POSITION attribute")
+	}
+	positions, err := l.accessorFloats(posIndex, 3)
+	if err != nil {
+		return fmt.Errorf("POSITION: %w", err)
+	}
+	count := len(positions) / 3
+	var normals, uvs []float32
+	if i, ok := prim.Attributes["NORMAL"]; ok {
+		if normals, err = l.accessorFloats(i, 3); err != nil {
+			return fmt.Errorf("NORMAL: %w", err)
+		}
+	}
+	if i, ok := prim.Attributes["TEXCOORD_0"]; ok {
+		// Core glTF also allows normalized unsigned byte/short texture coordinates.
+		if uvs, err = l.accessorFloats(i, 2, 5121, 5123); err != nil {
+			return fmt.Errorf("TEXCOORD_0: %w", err)
+		}
+	}
+
+	var indices []uint32
+	if prim.Indices != nil {
+		if indices, err = l.accessorIndices(*prim.Indices); err != nil {
+			return fmt.Errorf("indices: %w", err)
+		}
+	} else {
+		indices = make([]uint32, count)
+		for i := range indices {
+			indices[i] = uint32(i)
+		}
+	}
+	for _, idx := range indices {
+		if int(idx) >= count {
+			return fmt.Errorf("index %d out of range (%d vertices)", idx, count)
+		}
+	}
+
+	draw := meshDraw{
+		firstIndex:   uint32(len(l.out.indices)),
+		indexCount:   uint32(len(indices) / 3 * 3),
+		vertexOffset: int32(len(l.out.vertices)),
+		model:        model,
+	}
+	draw.baseColor, draw.texture = l.material(prim.Material)
+
+	for i := 0; i < count; i++ {
+		v := vertex{
+			pos:   mgl32.Vec3{positions[i*3], positions[i*3+1], positions[i*3+2]},
+			color: mgl32.Vec3{1, 1, 1},
+		}
+		var n mgl32.Vec3
+		if len(normals) >= (i+1)*3 {
+			n = mgl32.Vec3{normals[i*3], normals[i*3+1], normals[i*3+2]}
+		}
+		if len(uvs) >= (i+1)*2 {
+			v.uv = mgl32.Vec2{uvs[i*2], uvs[i*2+1]}
+		}
+		l.out.vertices = append(l.out.vertices, v)
+		l.out.normals = append(l.out.normals, n)
+	}
+	if normals == nil {
+		// glTF leaves missing normals to the client.
+		offset := int(draw.vertexOffset)
+		generateNormals(l.out.vertices[offset:], indices[:draw.indexCount], l.out.normals[offset:], nil)
+	}
+	l.out.indices = append(l.out.indices, indices[:draw.indexCount]...)
+	l.out.draws = append(l.out.draws, draw)
+	return nil
+}
+
+// material resolves a primitive's base color factor and mesh texture index. Primitives without
+// a base color texture sample a shared 1x1 white texture so the factor alone shows.
+func (l *gltfLoader) material(index *int) (mgl32.Vec4, int) {
+	color := mgl32.Vec4{1, 1, 1, 1}
+	if index == nil || *index >= len(l.doc.Materials) {
+		return color, l.whiteTexture()
+	}
+	mat := l.doc.Materials[*index]
+	if len(mat.PBR.BaseColorFactor) == 4 {
+		copy(color[:], mat.PBR.BaseColorFactor)
+	}
+	if mat.PBR.BaseColorTexture == nil {
+		return color, l.whiteTexture()
+	}
+	tex := mat.PBR.BaseColorTexture.Index
+	if tex >= len(l.doc.Textures) || l.doc.Textures[tex].Source == nil {
+		return color, l.whiteTexture()
+	}
+	image := *l.doc.Textures[tex].Source
+	if idx, ok := l.images[image]; ok {
+		return color, idx
+	}
+	src, err := l.loadImage(image)
+	if err != nil {
+		log.Printf("gltf: image %d: %v (using white)", image, err)
+		l.images[image] = l.whiteTexture()
+		return color, l.images[image]
+	}
+	l.images[image] = len(l.out.textures)
+	l.out.textures = append(l.out.textures, src)
+	return color, l.images[image]
+}
+
+// whiteTexture returns the mesh texture index of a 1x1 white texel, creating it on first use.
+func (l *gltfLoader) whiteTexture() int {
+	if l.white < 0 {
+		l.white = len(l.out.textures)
+		l.out.textures = append(l.out.textures, rgbaTextureSource(1, 1, []byte{255, 255, 255, 255}))
+	}
+	return l.white
+}
+
+// loadImage decodes a glTF image from a buffer view, data URI, or sibling file.
+func (l *gltfLoader) loadImage(index int) (textureSource, error) {
+	if index >= len(l.doc.Images) {
+		return textureSource{}, fmt.Errorf("out of range")
+	}
+	img := l.doc.Images[index]
+	var data []byte
+	var err error
+	if img.BufferView != nil {
+		data, _, err = l.bufferView(*img.BufferView)
+	} else {
+		data, err = l.readURI(img.URI)
+	}
+	if err != nil {
+		return textureSource{}, err
+	}
+	w, h, pixels, err := decodeImageRGBA(data)
+	if err != nil {
+		return textureSource{}, err
+	}
+	return rgbaTextureSource(w, h, pixels), nil
+}
+
+// bufferView returns the bytes of a buffer view and its stride (0 when tightly packed).
+func (l *gltfLoader) bufferView(index int) ([]byte, int, error) {
+	if index < 0 || index >= len(l.doc.BufferViews) {
+		return nil, 0, fmt.Errorf("bufferView %d out of range", index)
+	}
+	bv := l.doc.BufferViews[index]
+	if bv.Buffer < 0 || bv.Buffer >= len(l.buffers) {
+		return nil, 0, fmt.Errorf("bufferView %d: buffer %d out of range", index, bv.Buffer)
+	}
+	if bv.ByteOffset < 0 || bv.ByteLength < 0 {
+		return nil, 0, fmt.Errorf("bufferView %d: negative offset %d or length %d", index, bv.ByteOffset, bv.ByteLength)
+	}
+	if bv.ByteStride != 0 && (bv.ByteStride < 4 || bv.ByteStride > 252) {
+		return nil, 0, fmt.Errorf("bufferView %d: byteStride %d outside [4, 252]", index, bv.ByteStride)
+	}
+	buf := l.buffers[bv.Buffer]
+	if bv.ByteOffset > len(buf) || bv.ByteLength > len(buf)-bv.ByteOffset {
+		return nil, 0, fmt.Errorf("bufferView %d overruns buffer %d", index, bv.Buffer)
+	}
+	return buf[bv.ByteOffset : bv.ByteOffset+bv.ByteLength], bv.ByteStride, nil
+}
+
+// gltfComponentSize returns the byte size of an accessor component type.
+func gltfComponentSize(componentType int) int {
+	switch componentType {
+	case 5120, 5121: // BYTE, UNSIGNED_BYTE
+		return 1
+	case 5122, 5123: // SHORT, UNSIGNED_SHORT
+		return 2
+	case 5125, 5126: // UNSIGNED_INT, FLOAT
+		return 4
+	}
+	return 0
+}
+
+// gltfTypeComponents maps an accessor type to its component count.
+var gltfTypeComponents = map[string]int{"SCALAR": 1, "VEC2": 2, "VEC3": 3, "VEC4": 4, "MAT4": 16}
+
+// accessorElements walks an accessor's elements, handing each element's bytes to fn.
+func (l *gltfLoader) accessorElements(index int, fn func(elem []byte)) (gltfAccessor, error) {
+	if index < 0 || index >= len(l.doc.Accessors) {
+		return gltfAccessor{}, fmt.Errorf("accessor %d out of range", index)
+	}
+	acc := l.doc.Accessors[index]
+	if len(acc.Sparse) > 0 {
+		return acc, fmt.Errorf("accessor %d: sparse accessors are not supported", index)
+	}
+	comps := gltfTypeComponents[acc.Type]
+	size := gltfComponentSize(acc.ComponentType)
+	if comps == 0 || size == 0 {
+		return acc, fmt.Errorf("accessor %d: unsupported type %s/%d", index, acc.Type, acc.ComponentType)
+	}
+	elemSize := comps * size
+	if acc.Count < 0 || acc.ByteOffset < 0 {
+		return acc, fmt.Errorf("accessor %d: negative count %d or offset %d", index, acc.Count, acc.ByteOffset)
+	}
+	if acc.BufferView == nil {
+		// No buffer view means all zeros.
+		zero := make([]byte, elemSize)
+		for i := 0; i < acc.Count; i++ {
+			fn(zero)
+		}
+		return acc, nil
+	}
+	data, stride, err := l.bufferView(*acc.BufferView)
+	if err != nil {
+		return acc, err
+	}
+	if stride == 0 {
+		stride = elemSize
+	}
+	if stride < elemSize {
+		return acc, fmt.Errorf("accessor %d: byteStride %d is smaller than its %d-byte elements", index, stride, elemSize)
+	}
+	// Checked as a count of strides that fit, so a huge count can't overflow the product.
+	if acc.Count > 0 && (acc.ByteOffset > len(data)-elemSize || (len(data)-elemSize-acc.ByteOffset)/stride < acc.Count-1) {
+		return acc, fmt.Errorf("accessor %d overruns its buffer view", index)
+	}
+	for i := 0; i < acc.Count; i++ {
+		off := acc.ByteOffset + i*stride
+		fn(data[off : off+elemSize])
+	}
+	return acc, nil
+}
+
+// accessorFloats reads a float accessor with the expected component count. intTypes lists
+// the integer component types the attribute may also use, which must then be normalized.
+func (l *gltfLoader) accessorFloats(index, comps int, intTypes ...int) ([]float32, error) {
+	var out []float32
+	var componentType int
+	if index >= 0 && index < len(l.doc.Accessors) {
+		acc := l.doc.Accessors[index]
+		if gltfTypeComponents[acc.Type] != comps {
+			return nil, fmt.Errorf("accessor %d: expected %d components, got %s", index, comps, acc.Type)
+		}
+		componentType = acc.ComponentType
+		if componentType != 5126 && (!slices.Contains(intTypes, componentType) || !acc.Normalized) {
+			return nil, fmt.Errorf("accessor %d: component type %d (normalized %t) is not supported for this attribute", index, componentType, acc.Normalized)
+		}
+	}
+	size := gltfComponentSize(componentType)
+	_, err := l.accessorElements(index, func(elem []byte) {
+		for c := 0; c < comps; c++ {
+			out = append(out, gltfComponent(elem[c*size:], componentType))
+		}
+	})
+	return out, err
+}
+
+// gltfComponent decodes one float or normalized integer component, applying glTF's
+// normalized-integer rules.
+func gltfComponent(b []byte, componentType int) float32 {
+	le := binary.LittleEndian
+	switch componentType {
+	case 5126:
+		return math.Float32frombits(le.Uint32(b))
+	case 5121:
+		return float32(b[0]) / 255
+	case 5123:
+		return float32(le.Uint16(b)) / 65535
+	case 5120:
+		return float32(math.Max(float64(int8(b[0]))/127, -1))
+	case 5122:
+		return float32(math.Max(float64(int16(le.Uint16(b)))/32767, -1))
+	}
+	return 0
+}
+
+// accessorIndices reads an unsigned integer scalar accessor as 32-bit indices.
+func (l *gltfLoader) accessorIndices(index int) ([]uint32, error) {
+	var out []uint32
+	acc, err := l.accessorElements(index, func(elem []byte) {
+		switch len(elem) {
+		case 1:
+			out = append(out, uint32(elem[0]))
+		case 2:
+			out = append(out, uint32(binary.LittleEndian.Uint16(elem)))
+		case 4:
+			out = append(out, binary.LittleEndian.Uint32(elem))
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	if acc.Type != "SCALAR" || acc.ComponentType == 5126 {
+		return nil, fmt.Errorf("accessor %d: indices must be unsigned integer scalars", index)
+	}
+	return out, nil
+}
+
+// fitToCube prepends a translate+scale to every draw so the transformed scene fits in [-1, 1].
+func (l *gltfLoader) fitToCube() {
+	lo := mgl32.Vec3{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+	hi := lo.Mul(-1)
+	for _, d := range l.out.draws {
+		for _, idx := range l.out.indices[d.firstIndex : d.firstIndex+d.indexCount] {
+			p := mgl32.TransformCoordinate(l.out.vertices[int(d.vertexOffset)+int(idx)].pos, d.model)
+			for i := 0; i < 3; i++ {
+				lo[i] = float32(math.Min(float64(lo[i]), float64(p[i])))
+				hi[i] = float32(math.Max(float64(hi[i]), float64(p[i])))
+			}
+		}
+	}
+	if lo[0] > hi[0] {
+		return
+	}
+	center := lo.Add(hi).Mul(0.5)
+	extent := hi.Sub(lo).Mul(0.5)
+	half := float32(math.Max(float64(extent[0]), math.Max(float64(extent[1]), float64(extent[2]))))
+	if half == 0 {
+		half = 1
+	}
+	fit := mgl32.Scale3D(1/half, 1/half, 1/half).Mul4(mgl32.Translate3D(-center[0], -center[1], -center[2]))
+	for i := range l.out.draws {
+		l.out.draws[i].model = fit.Mul4(l.out.draws[i].model)
+	}
+}
@@ -0,0 +1,129 @@
+//go:build linux
+// +build linux
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/vulkan-go/vulkan"
+)
+
+// GPU timestamps written per frame: start of the command buffer, then after the mesh draws,
+// the skybox, and the overlay.
+const (
+	gpuStampStart = iota
+	gpuStampScene
+	gpuStampSkybox
+	gpuStampOverlay
+	gpuStampCount
+)
+
+// createGPUTimer makes a timestamp query pool with one set of stamps per frame in flight.
+// Devices whose graphics queue can't timestamp leave the pool null and skip GPU timing.
+func (a *VulkanApp) createGPUTimer() error {
+	var props vulkan.PhysicalDeviceProperties
+	vulkan.GetPhysicalDeviceProperties(a.physicalDevice, &props)
+	props.Deref()
+	props.Limits.Deref()
+
+	var count uint32
+	vulkan.GetPhysicalDeviceQueueFamilyProperties(a.physicalDevice, &count, nil)
+	families := make([]vulkan.QueueFamilyProperties, count)
+	vulkan.GetPhysicalDeviceQueueFamilyProperties(a.physicalDevice, &count, families)
+	if int(a.queues.graphicsFamily) >= len(families) {
+		return fmt.Errorf("graphics queue family %d not reported", a.queues.graphicsFamily)
+	}
+	family := families[a.queues.graphicsFamily]
+	family.Deref()
+	if family.TimestampValidBits == 0 || props.Limits.TimestampPeriod <= 0 {
+		return fmt.Errorf("graphics queue does not support timestamps")
+	}
+	a.gpuTimestampBits = family.TimestampValidBits
+	a.gpuTimestampPeriod = props.Limits.TimestampPeriod
+
+	poolInfo := vulkan.QueryPoolCreateInfo{
+		SType:      vulkan.StructureTypeQueryPoolCreateInfo,
+		QueryType:  vulkan.QueryTypeTimestamp,
+		QueryCount: gpuStampCount * maxFramesInFlight,
+	}
+	var zeroPool vulkan.QueryPool
+	poolOut := (*vulkan.QueryPool)(C.malloc(C.size_t(unsafe.Sizeof(zeroPool))))
+	if poolOut == nil {
+		return fmt.Errorf("allocate timestamp query pool handle")
+	}
+	defer C.free(unsafe.Pointer(poolOut))
+	if res := vulkan.CreateQueryPool(a.device, &poolInfo, nil, poolOut); res != vulkan.Success {
+		return fmt.Errorf("create timestamp query pool: %w", vulkan.Error(res))
+	}
+	a.gpuQueryPool = *poolOut
+	return nil
+}
+
+// destroyGPUTimer releases the timestamp query pool.
+func (a *VulkanApp) destroyGPUTimer() {
+	if a.gpuQueryPool != vulkan.QueryPool(vulkan.NullHandle) {
+		vulkan.DestroyQueryPool(a.device, a.gpuQueryPool, nil)
+		a.gpuQueryPool = vulkan.QueryPool(vulkan.NullHandle)
+	}
+}
+
+// beginGPUTimer resets this frame's stamps and writes the start one. It must be recorded
+// outside a render pass.
+func (a *VulkanApp) beginGPUTimer(cb vulkan.CommandBuffer) {
+	if a.gpuQueryPool == vulkan.QueryPool(vulkan.NullHandle) {
+		return
+	}
+	frame := a.currentFrame % maxFramesInFlight
+	vulkan.CmdResetQueryPool(cb, a.gpuQueryPool, uint32(frame*gpuStampCount), gpuStampCount)
+	vulkan.CmdWriteTimestamp(cb, vulkan.PipelineStageTopOfPipeBit, a.gpuQueryPool, uint32(frame*gpuStampCount+gpuStampStart))
+	a.gpuStampsWritten[frame] = true
+	if a.bench != nil {
+		a.gpuStampBenchFrame[frame] = a.bench.frames
+	}
+}
+
+// markGPUTimer records when the GPU finishes everything recorded before it.
+func (a *VulkanApp) markGPUTimer(cb vulkan.CommandBuffer, stamp int) {
+	if a.gpuQueryPool == vulkan.QueryPool(vulkan.NullHandle) {
+		return
+	}
+	frame := a.currentFrame % maxFramesInFlight
+	vulkan.CmdWriteTimestamp(cb, vulkan.PipelineStageBottomOfPipeBit, a.gpuQueryPool, uint32(frame*gpuStampCount+stamp))
+}
+
+// readGPUTimer collects the stamps of the frame that last used this slot. Call it after the
+// slot's fence has signaled so the results are final.
+func (a *VulkanApp) readGPUTimer(frame int) {
+	if a.gpuQueryPool == vulkan.QueryPool(vulkan.NullHandle) || !a.gpuStampsWritten[frame] {
+		return
+	}
+	var ticks [gpuStampCount]uint64
+	res := vulkan.GetQueryPoolResults(a.device, a.gpuQueryPool, uint32(frame*gpuStampCount), gpuStampCount,
+		uint(unsafe.Sizeof(ticks)), unsafe.Pointer(&ticks[0]), vulkan.DeviceSize(unsafe.Sizeof(ticks[0])),
+		vulkan.QueryResultFlags(vulkan.QueryResult64Bit))
+	if res != vulkan.Success {
+		return
+	}
+	a.gpuStampsWritten[frame] = false
+	span := func(from, to int) time.Duration {
+		return timestampDuration(ticks[from], ticks[to], a.gpuTimestampBits, a.gpuTimestampPeriod)
+	}
+	a.gpuTimes = gpuPassTimes{
+		scene:   span(gpuStampStart, gpuStampScene),
+		skybox:  span(gpuStampScene, gpuStampSkybox),
+		overlay: span(gpuStampSkybox, gpuStampOverlay),
+		total:   span(gpuStampStart, gpuStampOverlay),
+	}
+	a.gpuFrameTimes.record(a.gpuTimes.total)
+	if a.bench != nil {
+		a.bench.recordGPU(a.gpuStampBenchFrame[frame], a.gpuTimes.total)
+	}
+}
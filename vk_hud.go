@@ -0,0 +1,160 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+	"github.com/vulkan-go/vulkan"
+)
+
+// registerHUDPanels adds the built-in panels; config overrides are applied as they register.
+func (a *VulkanApp) registerHUDPanels() {
+	a.hud.add(hudPanel{name: "fps", anchor: anchorTopLeft, color: mgl32.Vec3{1, 1, 1}, scale: 3, verbosity: hudMinimal, update: func() string {
+		return fmt.Sprintf("FPS %.1f", a.fpsValue)
+	}})
+	a.hud.add(hudPanel{name: "clock", anchor: anchorTopLeft, color: mgl32.Vec3{1, 0.6, 0.2}, scale: 2, verbosity: hudMinimal, update: func() string {
+		var parts []string
+		if a.clock.paused {
+			parts = append(parts, fmt.Sprintf("PAUSED at step %d", a.clock.steps))
+		}
+		if a.clock.scale != 1 {
+			parts = append(parts, fmt.Sprintf("time x%g", a.clock.scale))
+		}
+		if a.replay != nil {
+			parts = append(parts, "REPLAY")
+		} else if a.inputRecorder != nil {
+			parts = append(parts, "REC")
+		}
+		return strings.Join(parts, "  ")
+	}})
+	a.hud.add(hudPanel{name: "frame", anchor: anchorBottomLeft, color: mgl32.Vec3{0.8, 0.8, 0.8}, scale: 2, verbosity: hudNormal, update: func() string {
+		s := a.frameTimes.summarize()
+		return fmt.Sprintf("frame %.2f ms\nmin %.1f avg %.1f max %.1f p99 %.1f", ms(a.frameTime), ms(s.min), ms(s.avg), ms(s.max), ms(s.p99))
+	}, graph: a.frameTimeGraph})
+	a.hud.add(hudPanel{name: "gpu", anchor: anchorBottomLeft, color: mgl32.Vec3{0.7, 0.9, 0.7}, scale: 2, verbosity: hudNormal, update: func() string {
+		if a.gpuQueryPool == vulkan.QueryPool(vulkan.NullHandle) {
+			return ""
+		}
+		t, s := a.gpuTimes, a.gpuFrameTimes.summarize()
+		return fmt.Sprintf("GPU %.2f ms (scene %.2f sky %.2f HUD %.2f)\navg %.2f p99 %.2f", ms(t.total), ms(t.scene), ms(t.skybox), ms(t.overlay), ms(s.avg), ms(s.p99))
+	}})
+	a.hud.add(hudPanel{name: "help", anchor: anchorTopLeft, color: mgl32.Vec3{1, 0.9, 0.4}, scale: 2, verbosity: hudMinimal, update: func() string {
+		if !a.helpVisible {
+			return ""
+		}
+		return strings.Join(a.cfg.keys.helpLines(), "\n")
+	}})
+	a.hud.add(hudPanel{name: "device", anchor: anchorTopRight, color: mgl32.Vec3{0.6, 0.85, 1}, scale: 2, verbosity: hudNormal, update: func() string {
+		return fmt.Sprintf("%s\n%s  %dx%d", a.deviceName, presentModeName(a.presentMode), a.swapchainExtent.Width, a.swapchainExtent.Height)
+	}})
+	a.hud.add(hudPanel{name: "pipeline", anchor: anchorTopRight, color: mgl32.Vec3{0.8, 0.8, 1}, scale: 2, verbosity: hudVerbose, update: func() string {
+		if a.statsQueryPool == vulkan.QueryPool(vulkan.NullHandle) {
+			return ""
+		}
+		return "mesh pass\n" + a.pipelineStats.describe()
+	}})
+	a.hud.add(hudPanel{name: "scene", anchor: anchorBottomLeft, color: mgl32.Vec3{0.8, 0.8, 0.8}, scale: 2, verbosity: hudVerbose, update: func() string {
+		cam := "orbit"
+		if a.camera.mode == cameraFly {
+			cam = fmt.Sprintf("fly (speed %.2f)", a.camera.fly.speed)
+		}
+		spin := "animation"
+		if a.animation == nil {
+			spin = fmt.Sprintf("%s spin %.1f deg/s", a.rotation.cfg.mode, a.rotation.cfg.degPerSec)
+		}
+		simSeconds := float64(a.clock.steps) * a.clock.step.Seconds()
+		return fmt.Sprintf("camera: %s\n%s\nstep %d (%.2fs)  frame %d\n%s", cam, spin, a.clock.steps, simSeconds, a.clock.frames, a.display.mode)
+	}})
+	for name := range a.cfg.hudPanels {
+		if a.hud.find(name) == nil {
+			log.Printf("config: hud_panels: no panel named %q", name)
+		}
+	}
+}
+
+// frameTimeGraph plots the recent CPU frame times in milliseconds against the 60 Hz budget.
+// The scale grows to fit spikes but never below two budgets, so a steady 60 FPS sits mid-height.
+func (a *VulkanApp) frameTimeGraph() *hudGraph {
+	times := a.frameTimes.ordered()
+	g := &hudGraph{values: make([]float32, len(times)), top: 2 * float32(ms(frameBudget)), ref: float32(ms(frameBudget)), width: frameHistorySize, height: 60}
+	for i, d := range times {
+		g.values[i] = float32(ms(d))
+		if g.values[i] > g.top {
+			g.top = g.values[i]
+		}
+	}
+	return g
+}
+
+// appendGraphVertices draws a panel's graph: one bar per value, colored by how far it is over
+// the reference, then the reference line on top.
+func (a *VulkanApp) appendGraphVertices(verts []overlayVertex, t hudText) []overlayVertex {
+	g := t.graph
+	if len(g.values) == 0 || g.top <= 0 {
+		return verts
+	}
+	barW := t.graphW / g.width
+	for i, v := range g.values {
+		if len(verts)+6 > maxOverlayVertices {
+			return verts
+		}
+		h := t.graphH * mgl32.Clamp(v/g.top, 0, 1)
+		color := mgl32.Vec4{0.3, 0.9, 0.3, 1}
+		if g.ref > 0 && v > 2*g.ref {
+			color = mgl32.Vec4{1, 0.25, 0.2, 1}
+		} else if g.ref > 0 && v > g.ref*1.05 {
+			color = mgl32.Vec4{1, 0.8, 0.2, 1}
+		}
+		x := t.graphX + t.graphW - float32(len(g.values)-i)*barW
+		verts = append(verts, quadToVertices(x, t.graphY+t.graphH-h, barW, h, solidUV, color, a.swapchainExtent)...)
+	}
+	if g.ref > 0 && g.ref <= g.top && len(verts)+6 <= maxOverlayVertices {
+		lineH := mgl32.Clamp(t.graphH/60, 1, 4)
+		y := t.graphY + t.graphH*(1-g.ref/g.top)
+		verts = append(verts, quadToVertices(t.graphX, y-lineH/2, t.graphW, lineH, solidUV, mgl32.Vec4{1, 1, 1, 0.8}, a.swapchainExtent)...)
+	}
+	return verts
+}
+
+// cycleHUDVerbosity steps through minimal, normal, and verbose HUD detail.
+func (a *VulkanApp) cycleHUDVerbosity() {
+	a.hudHidden = false
+	log.Printf("HUD: %s", a.hud.cycleVerbosity())
+}
+
+// buildOverlayVertices lays out every visible HUD panel as glyph quads over a translucent
+// backing so the text stays readable against the bright cube.
+func (a *VulkanApp) buildOverlayVertices() []overlayVertex {
+	var verts []overlayVertex
+	pad := hudPanelPadding * a.cfg.hudScale
+	for _, t := range a.hud.layout(float32(a.swapchainExtent.Width), float32(a.swapchainExtent.Height), a.cfg.hudScale) {
+		if a.cfg.hudBackground[3] > 0 && len(verts)+6 <= maxOverlayVertices {
+			verts = append(verts, quadToVertices(t.x-pad, t.y-pad, t.w+2*pad, t.h+2*pad, solidUV, a.cfg.hudBackground, a.swapchainExtent)...)
+		}
+		verts = a.appendOverlayText(verts, t.text, t.x, t.y, t.scale, t.color.Vec4(1))
+		if t.graph != nil {
+			verts = a.appendGraphVertices(verts, t)
+		}
+	}
+	return verts
+}
+
+// presentModeName is a short label for the HUD.
+func presentModeName(m vulkan.PresentMode) string {
+	switch m {
+	case vulkan.PresentModeImmediate:
+		return "immediate"
+	case vulkan.PresentModeMailbox:
+		return "mailbox"
+	case vulkan.PresentModeFifo:
+		return "fifo (vsync)"
+	case vulkan.PresentModeFifoRelaxed:
+		return "fifo relaxed"
+	}
+	return fmt.Sprintf("present mode %d", m)
+}
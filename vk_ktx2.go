@@ -0,0 +1,102 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/vulkan-go/vulkan"
+)
+
+// blockFormat describes a block-compressed Vulkan format and, when available, its CPU fallback decoder.
+type blockFormat struct {
+	blockWidth  uint32
+	blockHeight uint32
+	blockBytes  uint32
+	srgb        bool
+	decode      func(data []byte, width, height uint32) ([]byte, error)
+}
+
+func decodeBC1RGB(data []byte, w, h uint32) ([]byte, error)  { return decodeBC1(data, w, h, false) }
+func decodeBC1RGBA(data []byte, w, h uint32) ([]byte, error) { return decodeBC1(data, w, h, true) }
+
+// blockFormats lists the compressed formats accepted from KTX2 files.
+// ETC2 and ASTC have no CPU decoder and are only used when the device samples them natively.
+var blockFormats = map[vulkan.Format]blockFormat{
+	vulkan.FormatBc1RgbUnormBlock:       {4, 4, 8, false, decodeBC1RGB},
+	vulkan.FormatBc1RgbSrgbBlock:        {4, 4, 8, true, decodeBC1RGB},
+	vulkan.FormatBc1RgbaUnormBlock:      {4, 4, 8, false, decodeBC1RGBA},
+	vulkan.FormatBc1RgbaSrgbBlock:       {4, 4, 8, true, decodeBC1RGBA},
+	vulkan.FormatBc3UnormBlock:          {4, 4, 16, false, decodeBC3},
+	vulkan.FormatBc3SrgbBlock:           {4, 4, 16, true, decodeBC3},
+	vulkan.FormatBc7UnormBlock:          {4, 4, 16, false, decodeBC7},
+	vulkan.FormatBc7SrgbBlock:           {4, 4, 16, true, decodeBC7},
+	vulkan.FormatEtc2R8g8b8UnormBlock:   {4, 4, 8, false, nil},
+	vulkan.FormatEtc2R8g8b8SrgbBlock:    {4, 4, 8, true, nil},
+	vulkan.FormatEtc2R8g8b8a1UnormBlock: {4, 4, 8, false, nil},
+	vulkan.FormatEtc2R8g8b8a1SrgbBlock:  {4, 4, 8, true, nil},
+	vulkan.FormatEtc2R8g8b8a8UnormBlock: {4, 4, 16, false, nil},
+	vulkan.FormatEtc2R8g8b8a8SrgbBlock:  {4, 4, 16, true, nil},
+}
+
+// astcBlockSizes lists the ASTC footprints in VkFormat order, starting at VK_FORMAT_ASTC_4x4_UNORM_BLOCK.
+var astcBlockSizes = [][2]uint32{
+	{4, 4}, {5, 4}, {5, 5}, {6, 5}, {6, 6}, {8, 5}, {8, 6}, {8, 8},
+	{10, 5}, {10, 6}, {10, 8}, {10, 10}, {12, 10}, {12, 12},
+}
+
+func init() {
+	// Each ASTC footprint has a UNORM and an SRGB variant, in that order.
+	for i, size := range astcBlockSizes {
+		unorm := vulkan.FormatAstc4x4UnormBlock + vulkan.Format(2*i)
+		blockFormats[unorm] = blockFormat{size[0], size[1], 16, false, nil}
+		blockFormats[unorm+1] = blockFormat{size[0], size[1], 16, true, nil}
+	}
+}
+
+// textureLevelBytes returns the byte size of one layer of a mip level for the formats we upload.
+func textureLevelBytes(format vulkan.Format, width, height uint32) (int, error) {
+	switch format {
+	case vulkan.FormatR8g8b8a8Unorm, vulkan.FormatR8g8b8a8Srgb:
+		return int(uint64(width) * uint64(height) * 4), nil
+	}
+	bf, ok := blockFormats[format]
+	if !ok {
+		return 0, fmt.Errorf("unsupported texture format %v", format)
+	}
+	blocksX := (width + bf.blockWidth - 1) / bf.blockWidth
+	blocksY := (height + bf.blockHeight - 1) / bf.blockHeight
+	return int(uint64(blocksX) * uint64(blocksY) * uint64(bf.blockBytes)), nil
+}
+
+// textureFromKTX2 splits each KTX2 mip level into per-layer payloads. Array layers and cube faces
+// are flattened in file order (layer-major, then face).
+func textureFromKTX2(ktx *ktx2Texture) (textureSource, error) {
+	format := vulkan.Format(ktx.vkFormat)
+	layerCount := int(ktx.layers) * int(ktx.faces) // bounded by parseKTX2
+	src := textureSource{
+		format: format,
+		width:  ktx.width,
+		height: ktx.height,
+		layers: make([][][]byte, layerCount),
+	}
+	for level := range ktx.levels {
+		w, h := ktx.levelSize(level)
+		size, err := textureLevelBytes(format, w, h)
+		if err != nil {
+			return textureSource{}, err
+		}
+		data, err := ktx.levelData(level, size*layerCount)
+		if err != nil {
+			return textureSource{}, err
+		}
+		if len(data) < size*layerCount {
+			return textureSource{}, fmt.Errorf("ktx2 level %d truncated: got %d want %d", level, len(data), size*layerCount)
+		}
+		for layer := 0; layer < layerCount; layer++ {
+			src.layers[layer] = append(src.layers[layer], data[layer*size:(layer+1)*size])
+		}
+	}
+	return src, nil
+}
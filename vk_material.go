@@ -0,0 +1,154 @@
+//go:build linux
+// +build linux
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"reflect"
+	"unsafe"
+
+	"github.com/vulkan-go/vulkan"
+)
+
+// materialTexture is a sampled image owned by the loaded mesh's materials.
+type materialTexture struct {
+	image  vulkan.Image
+	memory vulkan.DeviceMemory
+	view   vulkan.ImageView
+}
+
+// createMaterialSetLayout defines set 1: the material's combined image sampler.
+func (a *VulkanApp) createMaterialSetLayout() error {
+	samplerBinding := vulkan.DescriptorSetLayoutBinding{
+		Binding:         0,
+		DescriptorType:  vulkan.DescriptorTypeCombinedImageSampler,
+		DescriptorCount: 1,
+		StageFlags:      vulkan.ShaderStageFlags(vulkan.ShaderStageFragmentBit),
+	}
+	layoutInfo := vulkan.DescriptorSetLayoutCreateInfo{
+		SType:        vulkan.StructureTypeDescriptorSetLayoutCreateInfo,
+		BindingCount: 1,
+		PBindings:    []vulkan.DescriptorSetLayoutBinding{samplerBinding},
+	}
+	var zero vulkan.DescriptorSetLayout
+	out := (*vulkan.DescriptorSetLayout)(C.malloc(C.size_t(unsafe.Sizeof(zero))))
+	if out == nil {
+		return fmt.Errorf("allocate material set layout handle")
+	}
+	defer C.free(unsafe.Pointer(out))
+	if res := vulkan.CreateDescriptorSetLayout(a.device, &layoutInfo, nil, out); res != vulkan.Success {
+		return fmt.Errorf("create material set layout: %w", vulkan.Error(res))
+	}
+	a.materialSetLayout = *out
+	return nil
+}
+
+// createMaterials uploads the mesh textures and writes one descriptor set per material:
+// set 0 holds the primary texture, followed by each mesh texture in order.
+func (a *VulkanApp) createMaterials() error {
+	views := []vulkan.ImageView{a.textureImageView}
+	for i, src := range a.mesh.textures {
+		image, memory, err := a.uploadTexture(src, 0)
+		if err != nil {
+			return fmt.Errorf("material texture %d: %w", i, err)
+		}
+		tex := materialTexture{image: image, memory: memory}
+		a.materialTextures = append(a.materialTextures, tex)
+		view, err := a.createImageViewLevels(image, src.format, vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit), vulkan.ImageViewType2dArray, src.mipLevels(), uint32(len(src.layers)))
+		if err != nil {
+			return fmt.Errorf("material texture %d: %w", i, err)
+		}
+		a.materialTextures[len(a.materialTextures)-1].view = view
+		views = append(views, view)
+	}
+
+	count := uint32(len(views))
+	poolInfo := vulkan.DescriptorPoolCreateInfo{
+		SType:         vulkan.StructureTypeDescriptorPoolCreateInfo,
+		MaxSets:       count,
+		PoolSizeCount: 1,
+		PPoolSizes: []vulkan.DescriptorPoolSize{
+			{Type: vulkan.DescriptorTypeCombinedImageSampler, DescriptorCount: count},
+		},
+	}
+	var zeroPool vulkan.DescriptorPool
+	poolOut := (*vulkan.DescriptorPool)(C.malloc(C.size_t(unsafe.Sizeof(zeroPool))))
+	if poolOut == nil {
+		return fmt.Errorf("allocate material descriptor pool handle")
+	}
+	defer C.free(unsafe.Pointer(poolOut))
+	if res := vulkan.CreateDescriptorPool(a.device, &poolInfo, nil, poolOut); res != vulkan.Success {
+		return fmt.Errorf("create material descriptor pool: %w", vulkan.Error(res))
+	}
+	a.materialDescriptorPool = *poolOut
+
+	layouts := make([]vulkan.DescriptorSetLayout, count)
+	for i := range layouts {
+		layouts[i] = a.materialSetLayout
+	}
+	allocInfo := vulkan.DescriptorSetAllocateInfo{
+		SType:              vulkan.StructureTypeDescriptorSetAllocateInfo,
+		DescriptorPool:     a.materialDescriptorPool,
+		DescriptorSetCount: count,
+		PSetLayouts:        layouts,
+	}
+	// Allocate descriptor set array in C memory to avoid Go pointer issues.
+	var zeroSet vulkan.DescriptorSet
+	cBuf := C.calloc(C.size_t(count), C.size_t(unsafe.Sizeof(zeroSet)))
+	if cBuf == nil {
+		return fmt.Errorf("allocate material descriptor set buffer")
+	}
+	defer C.free(cBuf)
+	sh := &reflect.SliceHeader{
+		Data: uintptr(cBuf),
+		Len:  int(count),
+		Cap:  int(count),
+	}
+	tmp := *(*[]vulkan.DescriptorSet)(unsafe.Pointer(sh))
+	if res := vulkan.AllocateDescriptorSets(a.device, &allocInfo, &tmp[0]); res != vulkan.Success {
+		return fmt.Errorf("allocate material descriptor sets: %w", vulkan.Error(res))
+	}
+	a.materialSets = make([]vulkan.DescriptorSet, count)
+	copy(a.materialSets, tmp)
+
+	for i, view := range views {
+		write := vulkan.WriteDescriptorSet{
+			SType:           vulkan.StructureTypeWriteDescriptorSet,
+			DstSet:          a.materialSets[i],
+			DstBinding:      0,
+			DescriptorType:  vulkan.DescriptorTypeCombinedImageSampler,
+			DescriptorCount: 1,
+			PImageInfo: []vulkan.DescriptorImageInfo{{
+				ImageLayout: vulkan.ImageLayoutShaderReadOnlyOptimal,
+				ImageView:   view,
+				Sampler:     a.textureSampler,
+			}},
+		}
+		vulkan.UpdateDescriptorSets(a.device, 1, []vulkan.WriteDescriptorSet{write}, 0, nil)
+	}
+	return nil
+}
+
+// destroyMaterials releases the material textures, descriptor pool, and set layout.
+func (a *VulkanApp) destroyMaterials() {
+	for _, tex := range a.materialTextures {
+		if tex.view != vulkan.ImageView(vulkan.NullHandle) {
+			vulkan.DestroyImageView(a.device, tex.view, nil)
+		}
+		vulkan.DestroyImage(a.device, tex.image, nil)
+		a.freeMemory(tex.memory)
+	}
+	a.materialTextures = nil
+	if a.materialDescriptorPool != vulkan.DescriptorPool(vulkan.NullHandle) {
+		vulkan.DestroyDescriptorPool(a.device, a.materialDescriptorPool, nil)
+	}
+	if a.materialSetLayout != vulkan.DescriptorSetLayout(vulkan.NullHandle) {
+		vulkan.DestroyDescriptorSetLayout(a.device, a.materialSetLayout, nil)
+	}
+}
@@ -0,0 +1,378 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+	"github.com/vulkan-go/vulkan"
+)
+
+// mesh is indexed triangle geometry ready for upload. Each draw covers a range of the shared
+// index buffer with its own model matrix and material.
+type mesh struct {
+	vertices    []vertex
+	normals     []mgl32.Vec3 // per vertex; kept off the GPU until the pipeline lights meshes
+	indices     []uint32
+	draws       []meshDraw
+	textures    []textureSource
+	texturePath string
+}
+
+// meshDraw is one indexed draw. Indices are relative to vertexOffset. texture indexes
+// mesh.textures, or is -1 for the app's primary texture.
+type meshDraw struct {
+	firstIndex   uint32
+	indexCount   uint32
+	vertexOffset int32
+	model        mgl32.Mat4
+	baseColor    mgl32.Vec4
+	texture      int
+}
+
+// drawPushConstants mirrors the DrawPush block shared by the cube vertex and fragment shaders.
+type drawPushConstants struct {
+	Model     mgl32.Mat4
+	BaseColor mgl32.Vec4
+}
+
+// wholeMeshDraw draws every index with the identity transform and the primary texture.
+func wholeMeshDraw(indexCount int) meshDraw {
+	return meshDraw{
+		indexCount: uint32(indexCount),
+		model:      mgl32.Ident4(),
+		baseColor:  mgl32.Vec4{1, 1, 1, 1},
+		texture:    -1,
+	}
+}
+
+// cubeMesh returns the built-in textured cube.
+func cubeMesh() mesh {
+	return mesh{vertices: cubeVertices, normals: cubeNormals, indices: cubeIndices, draws: []meshDraw{wholeMeshDraw(len(cubeIndices))}}
+}
+
+// indexType picks 16-bit indices when every index fits, halving index buffer size.
+func (m mesh) indexType() vulkan.IndexType {
+	for _, idx := range m.indices {
+		if idx > math.MaxUint16 {
+			return vulkan.IndexTypeUint32
+		}
+	}
+	return vulkan.IndexTypeUint16
+}
+
+// indexBytes encodes the index list at the width chosen by indexType.
+func (m mesh) indexBytes() []byte {
+	if m.indexType() == vulkan.IndexTypeUint32 {
+		return indicesToBytes(m.indices)
+	}
+	out := make([]byte, len(m.indices)*2)
+	for i, idx := range m.indices {
+		out[i*2] = byte(idx)
+		out[i*2+1] = byte(idx >> 8)
+	}
+	return out
+}
+
+// loadMesh resolves the OBJ or glTF model configured via `mesh:`, falling back to the built-in cube.
+func (a *VulkanApp) loadMesh() mesh {
+	if a.cfg.meshPath == "" {
+		return cubeMesh()
+	}
+	var (
+		m   mesh
+		err error
+	)
+	switch strings.ToLower(filepath.Ext(a.cfg.meshPath)) {
+	case ".gltf", ".glb":
+		m, err = loadGLTF(a.cfg.meshPath)
+	default:
+		m, err = loadOBJ(a.cfg.meshPath)
+		if err == nil {
+			fitMeshToCube(m.vertices)
+			for i := range m.draws {
+				m.draws[i].model = yUpToZUp.Mul4(m.draws[i].model)
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("load mesh %s failed, using cube: %v", a.cfg.meshPath, err)
+		return cubeMesh()
+	}
+	log.Printf("mesh: loaded %s (vertices=%d triangles=%d draws=%d textures=%d)", a.cfg.meshPath, len(m.vertices), len(m.indices)/3, len(m.draws), len(m.textures))
+	return m
+}
+
+// recordMeshDraws binds each draw's material and transform and issues its indexed draw.
+func (a *VulkanApp) recordMeshDraws(cb vulkan.CommandBuffer) {
+	stages := vulkan.ShaderStageFlags(vulkan.ShaderStageVertexBit | vulkan.ShaderStageFragmentBit)
+	for _, d := range a.mesh.draws {
+		// Material set 0 is the primary texture; mesh textures follow in order.
+		set := a.materialSets[d.texture+1]
+		vulkan.CmdBindDescriptorSets(cb, vulkan.PipelineBindPointGraphics, a.pipelineLayout, 1, 1, []vulkan.DescriptorSet{set}, 0, nil)
+		push := drawPushConstants{Model: d.model, BaseColor: d.baseColor}
+		vulkan.CmdPushConstants(cb, a.pipelineLayout, stages, 0, uint32(unsafe.Sizeof(push)), unsafe.Pointer(&push))
+		vulkan.CmdDrawIndexed(cb, d.indexCount, 1, d.firstIndex, d.vertexOffset, 0)
+	}
+}
+
+// yUpToZUp rotates +90 degrees about X so the +Y up of OBJ and glTF maps to Kube's +Z.
+var yUpToZUp = mgl32.HomogRotate3DX(math.Pi / 2)
+
+// fitMeshToCube recenters vertices on the origin and scales them into [-1, 1] so any model
+// fits the fixed camera framing used for the cube.
+func fitMeshToCube(verts []vertex) {
+	if len(verts) == 0 {
+		return
+	}
+	lo, hi := verts[0].pos, verts[0].pos
+	for _, v := range verts[1:] {
+		for i := 0; i < 3; i++ {
+			lo[i] = float32(math.Min(float64(lo[i]), float64(v.pos[i])))
+			hi[i] = float32(math.Max(float64(hi[i]), float64(v.pos[i])))
+		}
+	}
+	center := lo.Add(hi).Mul(0.5)
+	extent := hi.Sub(lo).Mul(0.5)
+	half := float32(math.Max(float64(extent[0]), math.Max(float64(extent[1]), float64(extent[2]))))
+	if half == 0 {
+		half = 1
+	}
+	for i := range verts {
+		verts[i].pos = verts[i].pos.Sub(center).Mul(1 / half)
+	}
+}
+
+// objKey identifies a unique position/texcoord/normal combination in an OBJ face.
+type objKey struct {
+	v, vt, vn int
+}
+
+// loadOBJ parses a Wavefront OBJ file into an indexed mesh, left in the file's Y-up space.
+// Polygons are fan-triangulated, missing normals are generated by averaging face normals, and
+// the first material with a diffuse map (map_Kd) supplies the texture.
+func loadOBJ(path string) (mesh, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return mesh{}, fmt.Errorf("open obj: %w", err)
+	}
+	defer f.Close()
+
+	var (
+		positions []mgl32.Vec3
+		texcoords []mgl32.Vec2
+		normals   []mgl32.Vec3
+		out       mesh
+		lookup    = map[objKey]uint32{}
+		materials = map[string]string{}
+		usedMtl   []string
+		generated []bool
+	)
+
+	resolve := func(idx string, count int) (int, error) {
+		if idx == "" {
+			return -1, nil
+		}
+		n, err := strconv.Atoi(idx)
+		if err != nil {
+			return 0, err
+		}
+		if n < 0 {
+			n += count
+		} else {
+			n--
+		}
+		if n < 0 || n >= count {
+			return 0, fmt.Errorf("index %s out of range (have %d)", idx, count)
+		}
+		return n, nil
+	}
+
+	vertexFor := func(token string) (uint32, error) {
+		parts := strings.Split(token, "/")
+		var key objKey
+		var err error
+		if key.v, err = resolve(parts[0], len(positions)); err != nil {
+			return 0, fmt.Errorf("position %w", err)
+		}
+		key.vt, key.vn = -1, -1
+		if len(parts) > 1 {
+			if key.vt, err = resolve(parts[1], len(texcoords)); err != nil {
+				return 0, fmt.Errorf("texcoord %w", err)
+			}
+		}
+		if len(parts) > 2 {
+			if key.vn, err = resolve(parts[2], len(normals)); err != nil {
+				return 0, fmt.Errorf("normal %w", err)
+			}
+		}
+		if idx, ok := lookup[key]; ok {
+			return idx, nil
+		}
+		v := vertex{pos: positions[key.v], color: mgl32.Vec3{1, 1, 1}}
+		if key.vt >= 0 {
+			// OBJ puts the texture origin bottom-left; Vulkan samples from the top-left.
+			v.uv = mgl32.Vec2{texcoords[key.vt][0], 1 - texcoords[key.vt][1]}
+		}
+		var n mgl32.Vec3
+		if key.vn >= 0 {
+			n = normals[key.vn]
+		}
+		idx := uint32(len(out.vertices))
+		out.vertices = append(out.vertices, v)
+		out.normals = append(out.normals, n)
+		generated = append(generated, key.vn < 0)
+		lookup[key] = idx
+		return idx, nil
+	}
+
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "v":
+			p, err := parseFloats(fields[1:], 3)
+			if err != nil {
+				return mesh{}, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			positions = append(positions, mgl32.Vec3{p[0], p[1], p[2]})
+		case "vt":
+			t, err := parseFloats(fields[1:], 2)
+			if err != nil {
+				return mesh{}, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			texcoords = append(texcoords, mgl32.Vec2{t[0], t[1]})
+		case "vn":
+			n, err := parseFloats(fields[1:], 3)
+			if err != nil {
+				return mesh{}, fmt.Errorf("%s:%d: %w", path, line, err)
+			}
+			normals = append(normals, mgl32.Vec3{n[0], n[1], n[2]})
+		case "f":
+			if len(fields) < 4 {
+				return mesh{}, fmt.Errorf("%s:%d: face needs at least 3 vertices", path, line)
+			}
+			corners := make([]uint32, 0, len(fields)-1)
+			for _, tok := range fields[1:] {
+				idx, err := vertexFor(tok)
+				if err != nil {
+					return mesh{}, fmt.Errorf("%s:%d: %w", path, line, err)
+				}
+				corners = append(corners, idx)
+			}
+			for i := 1; i+1 < len(corners); i++ {
+				out.indices = append(out.indices, corners[0], corners[i], corners[i+1])
+			}
+		case "mtllib":
+			for _, name := range fields[1:] {
+				mtlPath := filepath.Join(filepath.Dir(path), name)
+				if err := parseMTL(mtlPath, materials); err != nil {
+					log.Printf("mesh: %v", err)
+				}
+			}
+		case "usemtl":
+			if len(fields) > 1 {
+				usedMtl = append(usedMtl, fields[1])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return mesh{}, fmt.Errorf("read obj: %w", err)
+	}
+	if len(out.indices) == 0 {
+		return mesh{}, fmt.Errorf("%s: no faces", path)
+	}
+
+	generateNormals(out.vertices, out.indices, out.normals, generated)
+	out.draws = []meshDraw{wholeMeshDraw(len(out.indices))}
+	for _, name := range usedMtl {
+		if tex, ok := materials[name]; ok {
+			out.texturePath = tex
+			break
+		}
+	}
+	return out, nil
+}
+
+// generateNormals fills normals for vertices flagged in missing (every vertex when missing is
+// nil) by averaging the normals of the triangles that share them.
+func generateNormals(verts []vertex, indices []uint32, normals []mgl32.Vec3, missing []bool) {
+	fill := func(i uint32) bool { return missing == nil || missing[i] }
+	for i := 0; i+2 < len(indices); i += 3 {
+		a, b, c := verts[indices[i]].pos, verts[indices[i+1]].pos, verts[indices[i+2]].pos
+		n := b.Sub(a).Cross(c.Sub(a))
+		for _, idx := range indices[i : i+3] {
+			if fill(idx) {
+				normals[idx] = normals[idx].Add(n)
+			}
+		}
+	}
+	for i := range normals {
+		if fill(uint32(i)) && normals[i].Len() > 0 {
+			normals[i] = normals[i].Normalize()
+		}
+	}
+}
+
+// parseMTL records the diffuse texture (map_Kd) of each material in a .mtl file, resolved
+// relative to the .mtl location.
+func parseMTL(path string, materials map[string]string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open mtl: %w", err)
+	}
+	defer f.Close()
+
+	current := ""
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "newmtl":
+			current = fields[1]
+		case "map_Kd":
+			if current != "" {
+				// Options such as -s or -o may precede the file name, which always comes last.
+				materials[current] = filepath.Join(filepath.Dir(path), fields[len(fields)-1])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read mtl: %w", err)
+	}
+	return nil
+}
+
+// parseFloats parses at least n leading float fields.
+func parseFloats(fields []string, n int) ([]float32, error) {
+	if len(fields) < n {
+		return nil, fmt.Errorf("expected %d values, got %d", n, len(fields))
+	}
+	out := make([]float32, n)
+	for i := 0; i < n; i++ {
+		v, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = float32(v)
+	}
+	return out, nil
+}
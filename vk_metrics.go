@@ -0,0 +1,84 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"log"
+
+	"github.com/vulkan-go/vulkan"
+)
+
+// startMetricsServer serves the metrics endpoint when metrics_addr is configured. The
+// metrics themselves already exist so instance-time validation messages were counted.
+func (a *VulkanApp) startMetricsServer() {
+	if a.metrics == nil {
+		return
+	}
+	var memProps vulkan.PhysicalDeviceMemoryProperties
+	vulkan.GetPhysicalDeviceMemoryProperties(a.physicalDevice, &memProps)
+	memProps.Deref()
+	heaps := make([]memoryHeap, memProps.MemoryHeapCount)
+	for i := range heaps {
+		h := memProps.MemoryHeaps[i]
+		h.Deref()
+		heaps[i] = memoryHeap{size: uint64(h.Size), deviceLocal: h.Flags&vulkan.MemoryHeapFlags(vulkan.MemoryHeapDeviceLocalBit) != 0}
+	}
+	a.metrics.setHeaps(heaps)
+
+	srv, err := serveMetrics(a.cfg.metricsAddr, a.metrics)
+	if err != nil {
+		log.Printf("metrics: %v (endpoint disabled)", err)
+		return
+	}
+	a.metricsServer = srv
+	log.Printf("metrics: serving http://%s/metrics", a.cfg.metricsAddr)
+}
+
+// stopMetrics shuts the endpoint down.
+func (a *VulkanApp) stopMetrics() {
+	if a.metricsServer == nil {
+		return
+	}
+	stopMetricsServer(a.metricsServer)
+	a.metricsServer = nil
+}
+
+// allocateMemory wraps vkAllocateMemory, tracking the allocation for the GPU memory gauge.
+func (a *VulkanApp) allocateMemory(info *vulkan.MemoryAllocateInfo, out *vulkan.DeviceMemory) vulkan.Result {
+	res := vulkan.AllocateMemory(a.device, info, nil, out)
+	if res == vulkan.Success {
+		if a.gpuAllocations == nil {
+			a.gpuAllocations = make(map[vulkan.DeviceMemory]uint64)
+		}
+		a.gpuAllocations[*out] = uint64(info.AllocationSize)
+		a.gpuAllocated += uint64(info.AllocationSize)
+		a.metrics.setGPUMemory(a.gpuAllocated)
+	}
+	return res
+}
+
+// freeMemory wraps vkFreeMemory, untracking memory allocated through allocateMemory.
+func (a *VulkanApp) freeMemory(memory vulkan.DeviceMemory) {
+	vulkan.FreeMemory(a.device, memory, nil)
+	if size, ok := a.gpuAllocations[memory]; ok {
+		delete(a.gpuAllocations, memory)
+		a.gpuAllocated -= size
+		a.metrics.setGPUMemory(a.gpuAllocated)
+	}
+}
+
+// validationSeverity names the most severe bit of a debug report for the metrics label.
+func validationSeverity(flags vulkan.DebugReportFlags) string {
+	switch {
+	case flags&vulkan.DebugReportFlags(vulkan.DebugReportErrorBit) != 0:
+		return "error"
+	case flags&vulkan.DebugReportFlags(vulkan.DebugReportWarningBit) != 0:
+		return "warning"
+	case flags&vulkan.DebugReportFlags(vulkan.DebugReportPerformanceWarningBit) != 0:
+		return "performance_warning"
+	case flags&vulkan.DebugReportFlags(vulkan.DebugReportInformationBit) != 0:
+		return "information"
+	}
+	return "debug"
+}
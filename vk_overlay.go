@@ -19,7 +19,7 @@ import (
 	"github.com/vulkan-go/vulkan"
 )
 
-// createOverlayPipeline builds the HUD pipeline for the FPS text overlay.
+// createOverlayPipeline builds the alpha-blended HUD pipeline that draws atlas-textured quads.
 func (a *VulkanApp) createOverlayPipeline() error {
 	vertCode, err := os.ReadFile("shaders/overlay_vert.spv")
 	if err != nil {
@@ -64,7 +64,8 @@ func (a *VulkanApp) createOverlayPipeline() error {
 	}
 	attributeDescriptions := []vulkan.VertexInputAttributeDescription{
 		{Location: 0, Binding: 0, Format: vulkan.FormatR32g32Sfloat, Offset: uint32(unsafe.Offsetof(overlayVertex{}.pos))},
-		{Location: 1, Binding: 0, Format: vulkan.FormatR32g32b32Sfloat, Offset: uint32(unsafe.Offsetof(overlayVertex{}.color))},
+		{Location: 1, Binding: 0, Format: vulkan.FormatR32g32Sfloat, Offset: uint32(unsafe.Offsetof(overlayVertex{}.uv))},
+		{Location: 2, Binding: 0, Format: vulkan.FormatR32g32b32a32Sfloat, Offset: uint32(unsafe.Offsetof(overlayVertex{}.color))},
 	}
 
 	vertexInput := vulkan.PipelineVertexInputStateCreateInfo{
@@ -124,9 +125,16 @@ func (a *VulkanApp) createOverlayPipeline() error {
 		StencilTestEnable:     vulkan.False,
 	}
 
+	// Glyph coverage arrives as alpha, so text edges and translucent quads blend over the scene.
 	colorBlendAttachment := vulkan.PipelineColorBlendAttachmentState{
-		ColorWriteMask: vulkan.ColorComponentFlags(vulkan.ColorComponentRBit | vulkan.ColorComponentGBit | vulkan.ColorComponentBBit | vulkan.ColorComponentABit),
-		BlendEnable:    vulkan.False,
+		ColorWriteMask:      vulkan.ColorComponentFlags(vulkan.ColorComponentRBit | vulkan.ColorComponentGBit | vulkan.ColorComponentBBit | vulkan.ColorComponentABit),
+		BlendEnable:         vulkan.True,
+		SrcColorBlendFactor: vulkan.BlendFactorSrcAlpha,
+		DstColorBlendFactor: vulkan.BlendFactorOneMinusSrcAlpha,
+		ColorBlendOp:        vulkan.BlendOpAdd,
+		SrcAlphaBlendFactor: vulkan.BlendFactorOne,
+		DstAlphaBlendFactor: vulkan.BlendFactorOneMinusSrcAlpha,
+		AlphaBlendOp:        vulkan.BlendOpAdd,
 	}
 	colorBlending := vulkan.PipelineColorBlendStateCreateInfo{
 		SType:           vulkan.StructureTypePipelineColorBlendStateCreateInfo,
@@ -135,7 +143,9 @@ func (a *VulkanApp) createOverlayPipeline() error {
 	}
 
 	layoutInfo := vulkan.PipelineLayoutCreateInfo{
-		SType: vulkan.StructureTypePipelineLayoutCreateInfo,
+		SType:          vulkan.StructureTypePipelineLayoutCreateInfo,
+		SetLayoutCount: 1,
+		PSetLayouts:    []vulkan.DescriptorSetLayout{a.overlaySetLayout},
 	}
 	var zeroLayout vulkan.PipelineLayout
 	layoutOut := (*vulkan.PipelineLayout)(C.malloc(C.size_t(unsafe.Sizeof(zeroLayout))))
@@ -206,9 +216,14 @@ func (a *VulkanApp) createOverlayBuffers() error {
 	return nil
 }
 
-// updateFPSOverlay rebuilds overlay vertices for the current FPS string and updates buffers.
-func (a *VulkanApp) updateFPSOverlay() error {
+// updateOverlay measures the frame, lays out the HUD panels, and updates the overlay buffers.
+func (a *VulkanApp) updateOverlay() error {
 	now := time.Now()
+	if !a.lastFrameAt.IsZero() {
+		a.frameTime = now.Sub(a.lastFrameAt)
+		a.frameTimes.record(a.frameTime)
+	}
+	a.lastFrameAt = now
 	a.fpsFrameCount++
 	elapsed := now.Sub(a.fpsLastTime)
 	if elapsed >= time.Second {
@@ -217,7 +232,7 @@ func (a *VulkanApp) updateFPSOverlay() error {
 		a.fpsLastTime = now
 	}
 
-	verts := a.buildOverlayVertices(fmt.Sprintf("FPS: %.1f", a.fpsValue))
+	verts := a.buildOverlayVertices()
 	if len(verts) > maxOverlayVertices {
 		verts = verts[:maxOverlayVertices]
 	}
@@ -255,88 +270,177 @@ func (a *VulkanApp) updateFPSOverlay() error {
 	return nil
 }
 
-// buildOverlayVertices converts text into overlay quads using a tiny bitmap font.
-func (a *VulkanApp) buildOverlayVertices(text string) []overlayVertex {
+// appendOverlayText adds one textured quad per character with the text's top-left at pixel
+// (x, y); each font pixel covers scale x scale screen pixels, so any size works. Newlines
+// start a new line and tabs align to 4 columns.
+func (a *VulkanApp) appendOverlayText(verts []overlayVertex, text string, x, y, scale float32, color mgl32.Vec4) []overlayVertex {
 	if a.swapchainExtent.Width == 0 || a.swapchainExtent.Height == 0 {
-		return nil
-	}
-	cellW := float32(8)
-	cellH := float32(12)
-	margin := float32(8)
-	space := float32(4)
-	color := mgl32.Vec3{1, 1, 1}
-	var verts []overlayVertex
-	x := margin
-	y := margin
+		return verts
+	}
+	col, line := 0, 0
 	for _, ch := range text {
-		pattern := glyphPattern(ch)
-		if pattern == nil {
-			pattern = glyphPattern(' ')
-		}
-		if len(pattern) == 0 {
+		switch ch {
+		case '\n':
+			col = 0
+			line++
+			continue
+		case '\t':
+			col += fontTabStop - col%fontTabStop
+			continue
+		case ' ':
+			col++
 			continue
 		}
-		for row := 0; row < len(pattern); row++ {
-			for col := 0; col < len(pattern[row]); col++ {
-				if pattern[row][col] != '1' {
-					continue
-				}
-				px := x + float32(col)*cellW
-				py := y + float32(row)*cellH
-				verts = append(verts, quadToVertices(px, py, cellW, cellH, color, a.swapchainExtent)...)
-			}
-		}
-		x += float32(len(pattern[0]))*cellW + space
-		if len(verts) >= maxOverlayVertices {
-			return verts[:maxOverlayVertices]
+		if len(verts)+6 > maxOverlayVertices {
+			break
 		}
+		gx := x + float32(col*fontAdvance)*scale
+		gy := y + float32(line*fontLineHeight)*scale
+		verts = append(verts, quadToVertices(gx, gy, fontGlyphWidth*scale, fontGlyphHeight*scale, glyphUVFor(ch), color, a.swapchainExtent)...)
+		col++
 	}
 	return verts
 }
 
-// quadToVertices makes two triangles for a quad at pixel coords mapped to NDC.
-func quadToVertices(x, y, w, h float32, color mgl32.Vec3, extent vulkan.Extent2D) []overlayVertex {
+// quadToVertices makes two triangles for a textured quad at pixel coords mapped to NDC.
+func quadToVertices(x, y, w, h float32, uv glyphUV, color mgl32.Vec4, extent vulkan.Extent2D) []overlayVertex {
 	toNDC := func(px, py float32) mgl32.Vec2 {
 		nx := (px/float32(extent.Width))*2 - 1
 		ny := (py/float32(extent.Height))*2 - 1
 		return mgl32.Vec2{nx, ny}
 	}
-	p0 := toNDC(x, y)
-	p1 := toNDC(x+w, y)
-	p2 := toNDC(x+w, y+h)
-	p3 := toNDC(x, y+h)
-	return []overlayVertex{
-		{pos: p0, color: color},
-		{pos: p1, color: color},
-		{pos: p2, color: color},
-		{pos: p2, color: color},
-		{pos: p3, color: color},
-		{pos: p0, color: color},
+	v0 := overlayVertex{pos: toNDC(x, y), uv: mgl32.Vec2{uv.u0, uv.v0}, color: color}
+	v1 := overlayVertex{pos: toNDC(x+w, y), uv: mgl32.Vec2{uv.u1, uv.v0}, color: color}
+	v2 := overlayVertex{pos: toNDC(x+w, y+h), uv: mgl32.Vec2{uv.u1, uv.v1}, color: color}
+	v3 := overlayVertex{pos: toNDC(x, y+h), uv: mgl32.Vec2{uv.u0, uv.v1}, color: color}
+	return []overlayVertex{v0, v1, v2, v2, v3, v0}
+}
+
+// createOverlaySetLayout declares the overlay's single atlas sampler. It outlives the
+// swapchain-dependent overlay pipeline that references it.
+func (a *VulkanApp) createOverlaySetLayout() error {
+	binding := vulkan.DescriptorSetLayoutBinding{
+		Binding:         0,
+		DescriptorType:  vulkan.DescriptorTypeCombinedImageSampler,
+		DescriptorCount: 1,
+		StageFlags:      vulkan.ShaderStageFlags(vulkan.ShaderStageFragmentBit),
+	}
+	layoutInfo := vulkan.DescriptorSetLayoutCreateInfo{
+		SType:        vulkan.StructureTypeDescriptorSetLayoutCreateInfo,
+		BindingCount: 1,
+		PBindings:    []vulkan.DescriptorSetLayoutBinding{binding},
+	}
+	var zeroLayout vulkan.DescriptorSetLayout
+	layoutOut := (*vulkan.DescriptorSetLayout)(C.malloc(C.size_t(unsafe.Sizeof(zeroLayout))))
+	if layoutOut == nil {
+		return fmt.Errorf("allocate overlay descriptor set layout handle")
+	}
+	defer C.free(unsafe.Pointer(layoutOut))
+	if res := vulkan.CreateDescriptorSetLayout(a.device, &layoutInfo, nil, layoutOut); res != vulkan.Success {
+		return fmt.Errorf("create overlay descriptor set layout: %w", vulkan.Error(res))
+	}
+	a.overlaySetLayout = *layoutOut
+	return nil
+}
+
+// createOverlayAtlas uploads the glyph atlas generated from the HUD font and binds it with a
+// nearest-filtered sampler so scaled text stays crisp.
+func (a *VulkanApp) createOverlayAtlas() error {
+	src := textureSource{
+		format: vulkan.FormatR8Unorm,
+		width:  atlasWidth,
+		height: atlasHeight,
+		layers: [][][]byte{{buildGlyphAtlas()}},
+	}
+	image, memory, err := a.uploadTexture(src, 0)
+	if err != nil {
+		return fmt.Errorf("overlay atlas: %w", err)
+	}
+	a.overlayAtlasImage = image
+	a.overlayAtlasMemory = memory
+
+	view, err := a.createImageView(image, src.format, vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit))
+	if err != nil {
+		return fmt.Errorf("overlay atlas: %w", err)
+	}
+	a.overlayAtlasView = view
+
+	sampler, err := a.createFilteredSampler(vulkan.FilterNearest, vulkan.SamplerAddressModeClampToEdge, 0)
+	if err != nil {
+		return fmt.Errorf("overlay atlas: %w", err)
+	}
+	a.overlayAtlasSampler = sampler
+
+	poolInfo := vulkan.DescriptorPoolCreateInfo{
+		SType:         vulkan.StructureTypeDescriptorPoolCreateInfo,
+		MaxSets:       1,
+		PoolSizeCount: 1,
+		PPoolSizes: []vulkan.DescriptorPoolSize{
+			{Type: vulkan.DescriptorTypeCombinedImageSampler, DescriptorCount: 1},
+		},
+	}
+	var zeroPool vulkan.DescriptorPool
+	poolOut := (*vulkan.DescriptorPool)(C.malloc(C.size_t(unsafe.Sizeof(zeroPool))))
+	if poolOut == nil {
+		return fmt.Errorf("allocate overlay descriptor pool handle")
+	}
+	defer C.free(unsafe.Pointer(poolOut))
+	if res := vulkan.CreateDescriptorPool(a.device, &poolInfo, nil, poolOut); res != vulkan.Success {
+		return fmt.Errorf("create overlay descriptor pool: %w", vulkan.Error(res))
 	}
+	a.overlayDescriptorPool = *poolOut
+
+	allocInfo := vulkan.DescriptorSetAllocateInfo{
+		SType:              vulkan.StructureTypeDescriptorSetAllocateInfo,
+		DescriptorPool:     a.overlayDescriptorPool,
+		DescriptorSetCount: 1,
+		PSetLayouts:        []vulkan.DescriptorSetLayout{a.overlaySetLayout},
+	}
+	var zeroSet vulkan.DescriptorSet
+	setOut := (*vulkan.DescriptorSet)(C.malloc(C.size_t(unsafe.Sizeof(zeroSet))))
+	if setOut == nil {
+		return fmt.Errorf("allocate overlay descriptor set handle")
+	}
+	defer C.free(unsafe.Pointer(setOut))
+	if res := vulkan.AllocateDescriptorSets(a.device, &allocInfo, setOut); res != vulkan.Success {
+		return fmt.Errorf("allocate overlay descriptor set: %w", vulkan.Error(res))
+	}
+	a.overlayDescriptorSet = *setOut
+
+	write := vulkan.WriteDescriptorSet{
+		SType:           vulkan.StructureTypeWriteDescriptorSet,
+		DstSet:          a.overlayDescriptorSet,
+		DstBinding:      0,
+		DescriptorType:  vulkan.DescriptorTypeCombinedImageSampler,
+		DescriptorCount: 1,
+		PImageInfo: []vulkan.DescriptorImageInfo{{
+			ImageLayout: vulkan.ImageLayoutShaderReadOnlyOptimal,
+			ImageView:   a.overlayAtlasView,
+			Sampler:     a.overlayAtlasSampler,
+		}},
+	}
+	vulkan.UpdateDescriptorSets(a.device, 1, []vulkan.WriteDescriptorSet{write}, 0, nil)
+	return nil
 }
 
-// glyphPattern returns the bitmap rows for a supported character in the HUD font.
-func glyphPattern(ch rune) []string {
-	font := map[rune][]string{
-		'0': {"111", "101", "101", "101", "111"},
-		'1': {"010", "110", "010", "010", "111"},
-		'2': {"111", "001", "111", "100", "111"},
-		'3': {"111", "001", "111", "001", "111"},
-		'4': {"101", "101", "111", "001", "001"},
-		'5': {"111", "100", "111", "001", "111"},
-		'6': {"111", "100", "111", "101", "111"},
-		'7': {"111", "001", "001", "001", "001"},
-		'8': {"111", "101", "111", "101", "111"},
-		'9': {"111", "101", "111", "001", "111"},
-		'F': {"111", "100", "110", "100", "100"},
-		'P': {"111", "101", "111", "100", "100"},
-		'S': {"111", "100", "111", "001", "111"},
-		':': {"000", "010", "000", "010", "000"},
-		'.': {"000", "000", "000", "000", "010"},
-		' ': {"000", "000", "000", "000", "000"},
-	}
-	if p, ok := font[ch]; ok {
-		return p
-	}
-	return font[' ']
+// destroyOverlayAtlas releases the atlas image, sampler, and descriptors.
+func (a *VulkanApp) destroyOverlayAtlas() {
+	if a.overlayDescriptorPool != vulkan.DescriptorPool(vulkan.NullHandle) {
+		vulkan.DestroyDescriptorPool(a.device, a.overlayDescriptorPool, nil)
+	}
+	if a.overlaySetLayout != vulkan.DescriptorSetLayout(vulkan.NullHandle) {
+		vulkan.DestroyDescriptorSetLayout(a.device, a.overlaySetLayout, nil)
+	}
+	if a.overlayAtlasSampler != vulkan.Sampler(vulkan.NullHandle) {
+		vulkan.DestroySampler(a.device, a.overlayAtlasSampler, nil)
+	}
+	if a.overlayAtlasView != vulkan.ImageView(vulkan.NullHandle) {
+		vulkan.DestroyImageView(a.device, a.overlayAtlasView, nil)
+	}
+	if a.overlayAtlasImage != vulkan.Image(vulkan.NullHandle) {
+		vulkan.DestroyImage(a.device, a.overlayAtlasImage, nil)
+	}
+	if a.overlayAtlasMemory != vulkan.DeviceMemory(vulkan.NullHandle) {
+		a.freeMemory(a.overlayAtlasMemory)
+	}
 }
@@ -0,0 +1,101 @@
+//go:build linux
+// +build linux
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/vulkan-go/vulkan"
+)
+
+// pipelineStatFlags are the counters collected for the mesh pass; pipelineStats mirrors them.
+const pipelineStatFlags = vulkan.QueryPipelineStatisticInputAssemblyVerticesBit |
+	vulkan.QueryPipelineStatisticInputAssemblyPrimitivesBit |
+	vulkan.QueryPipelineStatisticVertexShaderInvocationsBit |
+	vulkan.QueryPipelineStatisticClippingInvocationsBit |
+	vulkan.QueryPipelineStatisticClippingPrimitivesBit |
+	vulkan.QueryPipelineStatisticFragmentShaderInvocationsBit
+
+// createPipelineStatsQueries makes one pipeline statistics query per frame in flight when the
+// device enabled the pipelineStatisticsQuery feature.
+func (a *VulkanApp) createPipelineStatsQueries() error {
+	if !a.pipelineStatsSupported {
+		return fmt.Errorf("device does not support pipelineStatisticsQuery")
+	}
+	poolInfo := vulkan.QueryPoolCreateInfo{
+		SType:              vulkan.StructureTypeQueryPoolCreateInfo,
+		QueryType:          vulkan.QueryTypePipelineStatistics,
+		QueryCount:         maxFramesInFlight,
+		PipelineStatistics: vulkan.QueryPipelineStatisticFlags(pipelineStatFlags),
+	}
+	var zeroPool vulkan.QueryPool
+	poolOut := (*vulkan.QueryPool)(C.malloc(C.size_t(unsafe.Sizeof(zeroPool))))
+	if poolOut == nil {
+		return fmt.Errorf("allocate pipeline statistics query pool handle")
+	}
+	defer C.free(unsafe.Pointer(poolOut))
+	if res := vulkan.CreateQueryPool(a.device, &poolInfo, nil, poolOut); res != vulkan.Success {
+		return fmt.Errorf("create pipeline statistics query pool: %w", vulkan.Error(res))
+	}
+	a.statsQueryPool = *poolOut
+	return nil
+}
+
+// destroyPipelineStatsQueries releases the pipeline statistics query pool.
+func (a *VulkanApp) destroyPipelineStatsQueries() {
+	if a.statsQueryPool != vulkan.QueryPool(vulkan.NullHandle) {
+		vulkan.DestroyQueryPool(a.device, a.statsQueryPool, nil)
+		a.statsQueryPool = vulkan.QueryPool(vulkan.NullHandle)
+	}
+}
+
+// resetPipelineStats clears this frame's query; it must be recorded outside a render pass.
+func (a *VulkanApp) resetPipelineStats(cb vulkan.CommandBuffer) {
+	if a.statsQueryPool == vulkan.QueryPool(vulkan.NullHandle) {
+		return
+	}
+	vulkan.CmdResetQueryPool(cb, a.statsQueryPool, uint32(a.currentFrame%maxFramesInFlight), 1)
+}
+
+// beginPipelineStats starts counting; pair it with endPipelineStats in the same subpass.
+func (a *VulkanApp) beginPipelineStats(cb vulkan.CommandBuffer) {
+	if a.statsQueryPool == vulkan.QueryPool(vulkan.NullHandle) {
+		return
+	}
+	vulkan.CmdBeginQuery(cb, a.statsQueryPool, uint32(a.currentFrame%maxFramesInFlight), 0)
+}
+
+// endPipelineStats stops counting and marks the frame's query for reading.
+func (a *VulkanApp) endPipelineStats(cb vulkan.CommandBuffer) {
+	if a.statsQueryPool == vulkan.QueryPool(vulkan.NullHandle) {
+		return
+	}
+	frame := a.currentFrame % maxFramesInFlight
+	vulkan.CmdEndQuery(cb, a.statsQueryPool, uint32(frame))
+	a.statsWritten[frame] = true
+}
+
+// readPipelineStats collects the counters of the frame that last used this slot, once its
+// fence has signaled.
+func (a *VulkanApp) readPipelineStats(frame int) {
+	if a.statsQueryPool == vulkan.QueryPool(vulkan.NullHandle) || !a.statsWritten[frame] {
+		return
+	}
+	var stats pipelineStats
+	size := unsafe.Sizeof(stats)
+	res := vulkan.GetQueryPoolResults(a.device, a.statsQueryPool, uint32(frame), 1,
+		uint(size), unsafe.Pointer(&stats), vulkan.DeviceSize(size),
+		vulkan.QueryResultFlags(vulkan.QueryResult64Bit))
+	if res != vulkan.Success {
+		return
+	}
+	a.statsWritten[frame] = false
+	a.pipelineStats = stats
+}
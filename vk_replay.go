@@ -0,0 +1,100 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// startInputSession opens the configured replay or recording. A replay takes priority and
+// restores the recorded clock settings and window size so steps line up exactly.
+func (a *VulkanApp) startInputSession() {
+	if a.cfg.inputReplayPath != "" {
+		replay, err := loadInputReplay(a.cfg.inputReplayPath)
+		if err != nil {
+			log.Printf("replay: %v (running live)", err)
+			return
+		}
+		h := replay.header
+		a.clock = newSimClock(clockConfig{stepHz: h.StepHz, timeScale: h.TimeScale})
+		if h.Width > 0 && h.Height > 0 {
+			a.window.SetSize(h.Width, h.Height)
+		}
+		a.replay = replay
+		log.Printf("replay: playing %s (%d events over %d frames); live input is ignored until it ends", a.cfg.inputReplayPath, len(replay.events), replay.lastFrame+1)
+		return
+	}
+	if a.cfg.inputRecordPath != "" {
+		width, height := a.window.GetSize()
+		header := inputEvent{Width: width, Height: height, StepHz: a.cfg.clock.stepHz, TimeScale: a.cfg.clock.timeScale}
+		rec, err := newInputRecorder(a.cfg.inputRecordPath, header)
+		if err != nil {
+			log.Printf("record: %v", err)
+			return
+		}
+		a.inputRecorder = rec
+		log.Printf("record: writing input to %s", a.cfg.inputRecordPath)
+	}
+}
+
+// recordInput appends ev, stamped with the current frame, when recording.
+func (a *VulkanApp) recordInput(ev inputEvent) {
+	if a.inputRecorder == nil {
+		return
+	}
+	ev.Frame = a.clock.frames
+	if err := a.inputRecorder.write(ev); err != nil {
+		log.Printf("record: %v (recording stopped)", err)
+		a.stopRecording()
+	}
+}
+
+// frameTiming advances the simulation clock for this frame and returns the steps to run and
+// the camera's wall-clock delta. During a replay both come from the recording.
+func (a *VulkanApp) frameTiming() (int, float32) {
+	if a.replay != nil {
+		steps, accum, dt, ok := a.replay.tick(a.clock.frames)
+		if !ok {
+			return 0, 0
+		}
+		a.clock.replayTick(steps, accum)
+		return steps, dt
+	}
+	now := time.Now()
+	steps := a.clock.tick(now)
+	var dt float32
+	if !a.lastCameraUpdate.IsZero() {
+		dt = float32(now.Sub(a.lastCameraUpdate).Seconds())
+	}
+	a.lastCameraUpdate = now
+	if a.inputRecorder != nil {
+		a.recordInput(inputEvent{Type: inputTick, Steps: steps, AccumNS: int64(a.clock.accum), DT: dt})
+		if a.inputRecorder != nil {
+			if err := a.inputRecorder.flush(); err != nil {
+				log.Printf("record: %v (recording stopped)", err)
+				a.stopRecording()
+			}
+		}
+	}
+	return steps, dt
+}
+
+// endReplay hands control back to live input once the recording is exhausted.
+func (a *VulkanApp) endReplay() {
+	log.Printf("replay: finished at frame %d; live input resumed", a.clock.frames)
+	a.replay = nil
+	a.lastCameraUpdate = time.Time{}
+}
+
+// stopRecording closes the recording file.
+func (a *VulkanApp) stopRecording() {
+	if a.inputRecorder == nil {
+		return
+	}
+	if err := a.inputRecorder.Close(); err != nil {
+		log.Printf("record: %v", err)
+	}
+	a.inputRecorder = nil
+}
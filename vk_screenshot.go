@@ -0,0 +1,145 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+	"unsafe"
+
+	"github.com/vulkan-go/vulkan"
+)
+
+// screenshotDir is where captured frames are written, relative to the working directory.
+const screenshotDir = "screenshots"
+
+// requestScreenshot captures the next presented frame to a PNG.
+func (a *VulkanApp) requestScreenshot() {
+	if !a.swapchainCanCapture {
+		log.Printf("Screenshot: swapchain images can't be used as a transfer source on this surface")
+		return
+	}
+	a.screenshotPending = true
+}
+
+// captureSwapchainImage copies a rendered swapchain image (in present layout) to host memory
+// and writes it as a PNG in the background. It must run after the frame's submit and before
+// its present; the copy is ordered after the frame's rendering by the queue.
+func (a *VulkanApp) captureSwapchainImage(imageIndex uint32) error {
+	var swapRB bool
+	switch a.swapchainFormat {
+	case vulkan.FormatB8g8r8a8Unorm, vulkan.FormatB8g8r8a8Srgb:
+		swapRB = true
+	case vulkan.FormatR8g8b8a8Unorm, vulkan.FormatR8g8b8a8Srgb:
+	default:
+		return fmt.Errorf("unsupported swapchain format %v for screenshots", a.swapchainFormat)
+	}
+	width, height := a.swapchainExtent.Width, a.swapchainExtent.Height
+	size := vulkan.DeviceSize(width) * vulkan.DeviceSize(height) * 4
+	buf, mem, err := a.createBuffer(size, vulkan.BufferUsageFlags(vulkan.BufferUsageTransferDstBit), vulkan.MemoryPropertyHostVisibleBit|vulkan.MemoryPropertyHostCoherentBit)
+	if err != nil {
+		return fmt.Errorf("create screenshot buffer: %w", err)
+	}
+	defer vulkan.DestroyBuffer(a.device, buf, nil)
+	defer a.freeMemory(mem)
+
+	src := a.swapchainImages[imageIndex]
+	subresource := vulkan.ImageSubresourceRange{
+		AspectMask: vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit),
+		LevelCount: 1,
+		LayerCount: 1,
+	}
+	err = a.oneTimeCommands(func(cb vulkan.CommandBuffer) {
+		toTransfer := vulkan.ImageMemoryBarrier{
+			SType:               vulkan.StructureTypeImageMemoryBarrier,
+			OldLayout:           vulkan.ImageLayoutPresentSrc,
+			NewLayout:           vulkan.ImageLayoutTransferSrcOptimal,
+			SrcQueueFamilyIndex: vulkan.QueueFamilyIgnored,
+			DstQueueFamilyIndex: vulkan.QueueFamilyIgnored,
+			Image:               src,
+			SubresourceRange:    subresource,
+			SrcAccessMask:       vulkan.AccessFlags(vulkan.AccessColorAttachmentWriteBit),
+			DstAccessMask:       vulkan.AccessFlags(vulkan.AccessTransferReadBit),
+		}
+		vulkan.CmdPipelineBarrier(cb,
+			vulkan.PipelineStageFlags(vulkan.PipelineStageColorAttachmentOutputBit),
+			vulkan.PipelineStageFlags(vulkan.PipelineStageTransferBit),
+			0, 0, nil, 0, nil, 1, []vulkan.ImageMemoryBarrier{toTransfer})
+
+		region := vulkan.BufferImageCopy{
+			ImageSubresource: vulkan.ImageSubresourceLayers{
+				AspectMask: vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit),
+				LayerCount: 1,
+			},
+			ImageExtent: vulkan.Extent3D{Width: width, Height: height, Depth: 1},
+		}
+		vulkan.CmdCopyImageToBuffer(cb, src, vulkan.ImageLayoutTransferSrcOptimal, buf, 1, []vulkan.BufferImageCopy{region})
+
+		toPresent := toTransfer
+		toPresent.OldLayout = vulkan.ImageLayoutTransferSrcOptimal
+		toPresent.NewLayout = vulkan.ImageLayoutPresentSrc
+		toPresent.SrcAccessMask = vulkan.AccessFlags(vulkan.AccessTransferReadBit)
+		toPresent.DstAccessMask = 0
+		vulkan.CmdPipelineBarrier(cb,
+			vulkan.PipelineStageFlags(vulkan.PipelineStageTransferBit),
+			vulkan.PipelineStageFlags(vulkan.PipelineStageBottomOfPipeBit),
+			0, 0, nil, 0, nil, 1, []vulkan.ImageMemoryBarrier{toPresent})
+	})
+	if err != nil {
+		return fmt.Errorf("copy swapchain image: %w", err)
+	}
+
+	var data unsafe.Pointer
+	if res := vulkan.MapMemory(a.device, mem, 0, size, 0, &data); res != vulkan.Success {
+		return fmt.Errorf("map screenshot buffer: %w", vulkan.Error(res))
+	}
+	img := &image.NRGBA{
+		Pix:    make([]byte, size),
+		Stride: int(width) * 4,
+		Rect:   image.Rect(0, 0, int(width), int(height)),
+	}
+	copy(img.Pix, (*[1 << 30]byte)(data)[:size:size])
+	vulkan.UnmapMemory(a.device, mem)
+
+	if swapRB {
+		for i := 0; i < len(img.Pix); i += 4 {
+			img.Pix[i], img.Pix[i+2] = img.Pix[i+2], img.Pix[i]
+		}
+	}
+	// The surface is composited opaque, so ignore whatever alpha the passes left behind.
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 0xff
+	}
+
+	path := filepath.Join(screenshotDir, fmt.Sprintf("kube-%s.png", time.Now().Format("20060102-150405.000")))
+	go func() {
+		if err := writePNG(path, img); err != nil {
+			log.Printf("Screenshot: %v", err)
+			return
+		}
+		log.Printf("Screenshot saved to %s", path)
+	}()
+	return nil
+}
+
+// writePNG encodes img to path, creating the parent directory if needed.
+func writePNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create screenshot: %w", err)
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("encode screenshot: %w", err)
+	}
+	return f.Close()
+}
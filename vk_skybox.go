@@ -0,0 +1,441 @@
+//go:build linux
+// +build linux
+
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unsafe"
+
+	mgl32 "github.com/go-gl/mathgl/mgl32"
+	"github.com/vulkan-go/vulkan"
+)
+
+// skyboxVertices expands the cube index list into a non-indexed triangle list of positions.
+func skyboxVertices() []mgl32.Vec3 {
+	out := make([]mgl32.Vec3, len(cubeIndices))
+	for i, idx := range cubeIndices {
+		out[i] = cubeVertices[idx].pos
+	}
+	return out
+}
+
+// skyboxEnabled reports whether the config asks for a skybox.
+func (a *VulkanApp) skyboxEnabled() bool {
+	return a.cfg.skyboxPath != "" || len(a.cfg.skyboxFacePaths) > 0
+}
+
+// loadSkyboxSource loads six face images, a KTX2 cube map, or an equirectangular panorama as six cube layers.
+func (a *VulkanApp) loadSkyboxSource() (textureSource, error) {
+	if len(a.cfg.skyboxFacePaths) > 0 {
+		return a.loadFaceTextures(a.cfg.skyboxFacePaths)
+	}
+	path := a.cfg.skyboxPath
+	if strings.EqualFold(filepath.Ext(path), ".ktx2") {
+		src, err := loadTextureFile(path)
+		if err != nil {
+			return textureSource{}, err
+		}
+		if len(src.layers) != int(cubeFaceCount) {
+			return textureSource{}, fmt.Errorf("%s: expected a cube map with %d faces, got %d layers", path, cubeFaceCount, len(src.layers))
+		}
+		return a.resolveTextureFormat(src)
+	}
+	width, height, pixels, err := loadImageFile(path)
+	if err != nil {
+		return textureSource{}, err
+	}
+	size := width / 4
+	if size == 0 || height == 0 {
+		return textureSource{}, fmt.Errorf("%s: panorama %dx%d too small", path, width, height)
+	}
+	src := textureSource{format: vulkan.FormatR8g8b8a8Srgb, width: size, height: size}
+	for _, face := range equirectToCubeFaces(width, height, pixels, size) {
+		src.layers = append(src.layers, [][]byte{face})
+	}
+	return src, nil
+}
+
+// createSkybox uploads the configured cube map and builds the skybox draw resources.
+// A skybox that fails to load is logged and skipped so the cube still renders.
+func (a *VulkanApp) createSkybox() error {
+	if !a.skyboxEnabled() {
+		return nil
+	}
+	src, err := a.loadSkyboxSource()
+	if err == nil && src.width != src.height {
+		err = fmt.Errorf("cube faces must be square, got %dx%d", src.width, src.height)
+	}
+	if err != nil {
+		log.Printf("skybox: load failed, disabling: %v", err)
+		return nil
+	}
+	log.Printf("skybox: loaded (format=%v %dx%d mips=%d)", src.format, src.width, src.height, src.mipLevels())
+
+	image, memory, err := a.uploadTexture(src, vulkan.ImageCreateFlags(vulkan.ImageCreateCubeCompatibleBit))
+	if err != nil {
+		return fmt.Errorf("skybox: %w", err)
+	}
+	a.skyboxImage = image
+	a.skyboxImageMemory = memory
+
+	view, err := a.createImageViewLevels(image, src.format, vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit), vulkan.ImageViewTypeCube, src.mipLevels(), cubeFaceCount)
+	if err != nil {
+		return fmt.Errorf("skybox: %w", err)
+	}
+	a.skyboxImageView = view
+
+	sampler, err := a.createSampler(vulkan.SamplerAddressModeClampToEdge, float32(src.mipLevels()))
+	if err != nil {
+		return fmt.Errorf("skybox: %w", err)
+	}
+	a.skyboxSampler = sampler
+
+	if err := a.createSkyboxDescriptors(); err != nil {
+		return err
+	}
+	if err := a.createSkyboxVertexBuffer(); err != nil {
+		return err
+	}
+	return a.createSkyboxPipeline()
+}
+
+// createSkyboxDescriptors builds the single-sampler layout, pool, and set used by the skybox pipeline.
+func (a *VulkanApp) createSkyboxDescriptors() error {
+	binding := vulkan.DescriptorSetLayoutBinding{
+		Binding:         0,
+		DescriptorType:  vulkan.DescriptorTypeCombinedImageSampler,
+		DescriptorCount: 1,
+		StageFlags:      vulkan.ShaderStageFlags(vulkan.ShaderStageFragmentBit),
+	}
+	layoutInfo := vulkan.DescriptorSetLayoutCreateInfo{
+		SType:        vulkan.StructureTypeDescriptorSetLayoutCreateInfo,
+		BindingCount: 1,
+		PBindings:    []vulkan.DescriptorSetLayoutBinding{binding},
+	}
+	var zeroLayout vulkan.DescriptorSetLayout
+	layoutOut := (*vulkan.DescriptorSetLayout)(C.malloc(C.size_t(unsafe.Sizeof(zeroLayout))))
+	if layoutOut == nil {
+		return fmt.Errorf("allocate skybox descriptor set layout handle")
+	}
+	defer C.free(unsafe.Pointer(layoutOut))
+	if res := vulkan.CreateDescriptorSetLayout(a.device, &layoutInfo, nil, layoutOut); res != vulkan.Success {
+		return fmt.Errorf("create skybox descriptor set layout: %w", vulkan.Error(res))
+	}
+	a.skyboxDescriptorSetLayout = *layoutOut
+
+	poolInfo := vulkan.DescriptorPoolCreateInfo{
+		SType:         vulkan.StructureTypeDescriptorPoolCreateInfo,
+		MaxSets:       1,
+		PoolSizeCount: 1,
+		PPoolSizes: []vulkan.DescriptorPoolSize{
+			{Type: vulkan.DescriptorTypeCombinedImageSampler, DescriptorCount: 1},
+		},
+	}
+	var zeroPool vulkan.DescriptorPool
+	poolOut := (*vulkan.DescriptorPool)(C.malloc(C.size_t(unsafe.Sizeof(zeroPool))))
+	if poolOut == nil {
+		return fmt.Errorf("allocate skybox descriptor pool handle")
+	}
+	defer C.free(unsafe.Pointer(poolOut))
+	if res := vulkan.CreateDescriptorPool(a.device, &poolInfo, nil, poolOut); res != vulkan.Success {
+		return fmt.Errorf("create skybox descriptor pool: %w", vulkan.Error(res))
+	}
+	a.skyboxDescriptorPool = *poolOut
+
+	allocInfo := vulkan.DescriptorSetAllocateInfo{
+		SType:              vulkan.StructureTypeDescriptorSetAllocateInfo,
+		DescriptorPool:     a.skyboxDescriptorPool,
+		DescriptorSetCount: 1,
+		PSetLayouts:        []vulkan.DescriptorSetLayout{a.skyboxDescriptorSetLayout},
+	}
+	var zeroSet vulkan.DescriptorSet
+	setOut := (*vulkan.DescriptorSet)(C.malloc(C.size_t(unsafe.Sizeof(zeroSet))))
+	if setOut == nil {
+		return fmt.Errorf("allocate skybox descriptor set handle")
+	}
+	defer C.free(unsafe.Pointer(setOut))
+	if res := vulkan.AllocateDescriptorSets(a.device, &allocInfo, setOut); res != vulkan.Success {
+		return fmt.Errorf("allocate skybox descriptor set: %w", vulkan.Error(res))
+	}
+	a.skyboxDescriptorSet = *setOut
+
+	write := vulkan.WriteDescriptorSet{
+		SType:           vulkan.StructureTypeWriteDescriptorSet,
+		DstSet:          a.skyboxDescriptorSet,
+		DstBinding:      0,
+		DescriptorType:  vulkan.DescriptorTypeCombinedImageSampler,
+		DescriptorCount: 1,
+		PImageInfo: []vulkan.DescriptorImageInfo{{
+			ImageLayout: vulkan.ImageLayoutShaderReadOnlyOptimal,
+			ImageView:   a.skyboxImageView,
+			Sampler:     a.skyboxSampler,
+		}},
+	}
+	vulkan.UpdateDescriptorSets(a.device, 1, []vulkan.WriteDescriptorSet{write}, 0, nil)
+	return nil
+}
+
+// createSkyboxVertexBuffer uploads the skybox cube positions into a host-visible vertex buffer.
+func (a *VulkanApp) createSkyboxVertexBuffer() error {
+	verts := skyboxVertices()
+	bufferSize := vulkan.DeviceSize(len(verts)) * vulkan.DeviceSize(unsafe.Sizeof(mgl32.Vec3{}))
+	buf, mem, err := a.createBuffer(bufferSize, vulkan.BufferUsageFlags(vulkan.BufferUsageVertexBufferBit), vulkan.MemoryPropertyHostVisibleBit|vulkan.MemoryPropertyHostCoherentBit)
+	if err != nil {
+		return fmt.Errorf("create skybox vertex buffer: %w", err)
+	}
+	a.skyboxVertexBuffer = buf
+	a.skyboxVertexMemory = mem
+	a.skyboxVertexCount = uint32(len(verts))
+
+	var data unsafe.Pointer
+	if res := vulkan.MapMemory(a.device, mem, 0, bufferSize, 0, &data); res != vulkan.Success {
+		return fmt.Errorf("map skybox vertex buffer: %w", vulkan.Error(res))
+	}
+	dst := (*[1 << 30]byte)(data)[:bufferSize:bufferSize]
+	copy(dst, (*[1 << 30]byte)(unsafe.Pointer(&verts[0]))[:bufferSize:bufferSize])
+	vulkan.UnmapMemory(a.device, mem)
+	return nil
+}
+
+// createSkyboxPipeline builds the skybox pipeline: depth-tested at the far plane, no depth writes.
+// It is a no-op when no skybox was loaded.
+func (a *VulkanApp) createSkyboxPipeline() error {
+	if a.skyboxImageView == vulkan.ImageView(vulkan.NullHandle) {
+		return nil
+	}
+	vertCode, err := os.ReadFile("shaders/skybox_vert.spv")
+	if err != nil {
+		return fmt.Errorf("read skybox vertex shader: %w", err)
+	}
+	fragCode, err := os.ReadFile("shaders/skybox_frag.spv")
+	if err != nil {
+		return fmt.Errorf("read skybox fragment shader: %w", err)
+	}
+
+	vertModule, err := a.createShaderModule(vertCode)
+	if err != nil {
+		return err
+	}
+	defer vulkan.DestroyShaderModule(a.device, vertModule, nil)
+	fragModule, err := a.createShaderModule(fragCode)
+	if err != nil {
+		return err
+	}
+	defer vulkan.DestroyShaderModule(a.device, fragModule, nil)
+
+	mainName := "main\x00"
+	shaderStages := []vulkan.PipelineShaderStageCreateInfo{
+		{
+			SType:  vulkan.StructureTypePipelineShaderStageCreateInfo,
+			Stage:  vulkan.ShaderStageVertexBit,
+			Module: vertModule,
+			PName:  mainName,
+		},
+		{
+			SType:  vulkan.StructureTypePipelineShaderStageCreateInfo,
+			Stage:  vulkan.ShaderStageFragmentBit,
+			Module: fragModule,
+			PName:  mainName,
+		},
+	}
+
+	bindingDescription := vulkan.VertexInputBindingDescription{
+		Binding:   0,
+		Stride:    uint32(unsafe.Sizeof(mgl32.Vec3{})),
+		InputRate: vulkan.VertexInputRateVertex,
+	}
+	attributeDescriptions := []vulkan.VertexInputAttributeDescription{
+		{Location: 0, Binding: 0, Format: vulkan.FormatR32g32b32Sfloat, Offset: 0},
+	}
+	vertexInput := vulkan.PipelineVertexInputStateCreateInfo{
+		SType:                           vulkan.StructureTypePipelineVertexInputStateCreateInfo,
+		VertexBindingDescriptionCount:   1,
+		PVertexBindingDescriptions:      []vulkan.VertexInputBindingDescription{bindingDescription},
+		VertexAttributeDescriptionCount: uint32(len(attributeDescriptions)),
+		PVertexAttributeDescriptions:    attributeDescriptions,
+	}
+
+	inputAssembly := vulkan.PipelineInputAssemblyStateCreateInfo{
+		SType:                  vulkan.StructureTypePipelineInputAssemblyStateCreateInfo,
+		Topology:               vulkan.PrimitiveTopologyTriangleList,
+		PrimitiveRestartEnable: vulkan.False,
+	}
+
+	viewport := vulkan.Viewport{
+		X:        0,
+		Y:        0,
+		Width:    float32(a.swapchainExtent.Width),
+		Height:   float32(a.swapchainExtent.Height),
+		MinDepth: 0,
+		MaxDepth: 1,
+	}
+	scissor := vulkan.Rect2D{
+		Offset: vulkan.Offset2D{X: 0, Y: 0},
+		Extent: a.swapchainExtent,
+	}
+	viewportState := vulkan.PipelineViewportStateCreateInfo{
+		SType:         vulkan.StructureTypePipelineViewportStateCreateInfo,
+		ViewportCount: 1,
+		PViewports:    []vulkan.Viewport{viewport},
+		ScissorCount:  1,
+		PScissors:     []vulkan.Rect2D{scissor},
+	}
+
+	rasterizer := vulkan.PipelineRasterizationStateCreateInfo{
+		SType:                   vulkan.StructureTypePipelineRasterizationStateCreateInfo,
+		RasterizerDiscardEnable: vulkan.False,
+		PolygonMode:             vulkan.PolygonModeFill,
+		LineWidth:               1.0,
+		// The camera sits inside the cube, so draw both windings.
+		CullMode:  vulkan.CullModeFlags(vulkan.CullModeNone),
+		FrontFace: vulkan.FrontFaceCounterClockwise,
+	}
+
+	multisampling := vulkan.PipelineMultisampleStateCreateInfo{
+		SType:                vulkan.StructureTypePipelineMultisampleStateCreateInfo,
+		RasterizationSamples: vulkan.SampleCount1Bit,
+	}
+
+	// The vertex shader writes depth 1.0; LESS_OR_EQUAL passes only where nothing nearer was drawn.
+	depthStencil := vulkan.PipelineDepthStencilStateCreateInfo{
+		SType:                 vulkan.StructureTypePipelineDepthStencilStateCreateInfo,
+		DepthTestEnable:       vulkan.True,
+		DepthWriteEnable:      vulkan.False,
+		DepthCompareOp:        vulkan.CompareOpLessOrEqual,
+		DepthBoundsTestEnable: vulkan.False,
+		StencilTestEnable:     vulkan.False,
+	}
+
+	colorBlendAttachment := vulkan.PipelineColorBlendAttachmentState{
+		ColorWriteMask: vulkan.ColorComponentFlags(vulkan.ColorComponentRBit | vulkan.ColorComponentGBit | vulkan.ColorComponentBBit | vulkan.ColorComponentABit),
+		BlendEnable:    vulkan.False,
+	}
+	colorBlending := vulkan.PipelineColorBlendStateCreateInfo{
+		SType:           vulkan.StructureTypePipelineColorBlendStateCreateInfo,
+		AttachmentCount: 1,
+		PAttachments:    []vulkan.PipelineColorBlendAttachmentState{colorBlendAttachment},
+	}
+
+	layoutInfo := vulkan.PipelineLayoutCreateInfo{
+		SType:                  vulkan.StructureTypePipelineLayoutCreateInfo,
+		SetLayoutCount:         1,
+		PSetLayouts:            []vulkan.DescriptorSetLayout{a.skyboxDescriptorSetLayout},
+		PushConstantRangeCount: 1,
+		PPushConstantRanges: []vulkan.PushConstantRange{{
+			StageFlags: vulkan.ShaderStageFlags(vulkan.ShaderStageVertexBit),
+			Offset:     0,
+			Size:       uint32(unsafe.Sizeof(mgl32.Mat4{})),
+		}},
+	}
+	var zeroLayout vulkan.PipelineLayout
+	layoutOut := (*vulkan.PipelineLayout)(C.malloc(C.size_t(unsafe.Sizeof(zeroLayout))))
+	if layoutOut == nil {
+		return fmt.Errorf("allocate skybox pipeline layout handle")
+	}
+	defer C.free(unsafe.Pointer(layoutOut))
+	if res := vulkan.CreatePipelineLayout(a.device, &layoutInfo, nil, layoutOut); res != vulkan.Success {
+		return fmt.Errorf("create skybox pipeline layout: %w", vulkan.Error(res))
+	}
+	a.skyboxPipelineLayout = *layoutOut
+
+	pipelineInfo := vulkan.GraphicsPipelineCreateInfo{
+		SType:               vulkan.StructureTypeGraphicsPipelineCreateInfo,
+		StageCount:          uint32(len(shaderStages)),
+		PStages:             shaderStages,
+		PVertexInputState:   &vertexInput,
+		PInputAssemblyState: &inputAssembly,
+		PViewportState:      &viewportState,
+		PRasterizationState: &rasterizer,
+		PMultisampleState:   &multisampling,
+		PDepthStencilState:  &depthStencil,
+		PColorBlendState:    &colorBlending,
+		Layout:              a.skyboxPipelineLayout,
+		RenderPass:          a.renderPass,
+		Subpass:             0,
+	}
+
+	var zeroPipeline vulkan.Pipeline
+	cBuf := C.calloc(C.size_t(1), C.size_t(unsafe.Sizeof(zeroPipeline)))
+	if cBuf == nil {
+		vulkan.DestroyPipelineLayout(a.device, a.skyboxPipelineLayout, nil)
+		return fmt.Errorf("allocate skybox pipeline buffer")
+	}
+	defer C.free(cBuf)
+	sh := &reflect.SliceHeader{
+		Data: uintptr(cBuf),
+		Len:  1,
+		Cap:  1,
+	}
+	pipelines := *(*[]vulkan.Pipeline)(unsafe.Pointer(sh))
+
+	if res := vulkan.CreateGraphicsPipelines(a.device, vulkan.PipelineCache(vulkan.NullHandle), 1, []vulkan.GraphicsPipelineCreateInfo{pipelineInfo}, nil, pipelines); res != vulkan.Success {
+		vulkan.DestroyPipelineLayout(a.device, a.skyboxPipelineLayout, nil)
+		return fmt.Errorf("create skybox pipeline: %w", vulkan.Error(res))
+	}
+	a.skyboxPipeline = pipelines[0]
+	return nil
+}
+
+// recordSkybox draws the skybox after the opaque geometry so only uncovered pixels are shaded.
+func (a *VulkanApp) recordSkybox(cb vulkan.CommandBuffer) {
+	if a.skyboxPipeline == vulkan.Pipeline(vulkan.NullHandle) {
+		return
+	}
+	vulkan.CmdBindPipeline(cb, vulkan.PipelineBindPointGraphics, a.skyboxPipeline)
+	vulkan.CmdBindVertexBuffers(cb, 0, 1, []vulkan.Buffer{a.skyboxVertexBuffer}, []vulkan.DeviceSize{0})
+	vulkan.CmdBindDescriptorSets(cb, vulkan.PipelineBindPointGraphics, a.skyboxPipelineLayout, 0, 1, []vulkan.DescriptorSet{a.skyboxDescriptorSet}, 0, nil)
+	viewProj := a.skyboxViewProj
+	vulkan.CmdPushConstants(cb, a.skyboxPipelineLayout, vulkan.ShaderStageFlags(vulkan.ShaderStageVertexBit), 0, uint32(unsafe.Sizeof(viewProj)), unsafe.Pointer(&viewProj))
+	vulkan.CmdDraw(cb, a.skyboxVertexCount, 1, 0, 0)
+}
+
+// destroySkyboxPipeline releases the swapchain-dependent skybox pipeline objects.
+func (a *VulkanApp) destroySkyboxPipeline() {
+	if a.skyboxPipeline != vulkan.Pipeline(vulkan.NullHandle) {
+		vulkan.DestroyPipeline(a.device, a.skyboxPipeline, nil)
+		a.skyboxPipeline = vulkan.Pipeline(vulkan.NullHandle)
+	}
+	if a.skyboxPipelineLayout != vulkan.PipelineLayout(vulkan.NullHandle) {
+		vulkan.DestroyPipelineLayout(a.device, a.skyboxPipelineLayout, nil)
+		a.skyboxPipelineLayout = vulkan.PipelineLayout(vulkan.NullHandle)
+	}
+}
+
+// destroySkybox releases the skybox image, sampler, descriptors, and vertex buffer.
+func (a *VulkanApp) destroySkybox() {
+	if a.skyboxVertexBuffer != vulkan.Buffer(vulkan.NullHandle) {
+		vulkan.DestroyBuffer(a.device, a.skyboxVertexBuffer, nil)
+	}
+	if a.skyboxVertexMemory != vulkan.DeviceMemory(vulkan.NullHandle) {
+		a.freeMemory(a.skyboxVertexMemory)
+	}
+	if a.skyboxDescriptorPool != vulkan.DescriptorPool(vulkan.NullHandle) {
+		vulkan.DestroyDescriptorPool(a.device, a.skyboxDescriptorPool, nil)
+	}
+	if a.skyboxDescriptorSetLayout != vulkan.DescriptorSetLayout(vulkan.NullHandle) {
+		vulkan.DestroyDescriptorSetLayout(a.device, a.skyboxDescriptorSetLayout, nil)
+	}
+	if a.skyboxSampler != vulkan.Sampler(vulkan.NullHandle) {
+		vulkan.DestroySampler(a.device, a.skyboxSampler, nil)
+	}
+	if a.skyboxImageView != vulkan.ImageView(vulkan.NullHandle) {
+		vulkan.DestroyImageView(a.device, a.skyboxImageView, nil)
+	}
+	if a.skyboxImage != vulkan.Image(vulkan.NullHandle) {
+		vulkan.DestroyImage(a.device, a.skyboxImage, nil)
+	}
+	if a.skyboxImageMemory != vulkan.DeviceMemory(vulkan.NullHandle) {
+		a.freeMemory(a.skyboxImageMemory)
+	}
+}
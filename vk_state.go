@@ -0,0 +1,34 @@
+//go:build linux
+// +build linux
+
+package main
+
+import "log"
+
+// restoreState applies the saved camera pose, pause, and HUD visibility. Recording and replay
+// start from the configured defaults instead so the session stays reproducible.
+func (a *VulkanApp) restoreState(st *savedState) {
+	a.hudHidden = st.HUDHidden
+	if a.cfg.inputRecordPath != "" || a.cfg.inputReplayPath != "" {
+		return
+	}
+	a.camera.restore(st.Camera)
+	if st.Paused && !a.clock.paused {
+		a.togglePause()
+	}
+}
+
+// saveState writes the current window, camera, pause, and HUD state to path.
+func (a *VulkanApp) saveState(path string) {
+	st := savedState{
+		Window:    a.display.snapshot(a.window),
+		Camera:    a.camera.snapshot(),
+		Paused:    a.clock.paused,
+		HUDHidden: a.hudHidden,
+	}
+	if err := st.save(path); err != nil {
+		log.Printf("state: %v", err)
+		return
+	}
+	log.Printf("state: saved to %s", path)
+}
@@ -0,0 +1,75 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"log"
+	"time"
+)
+
+// frameStamps are the wall-clock points DrawFrame reached this frame, for the stats export.
+type frameStamps struct {
+	acquired   time.Time
+	recorded   time.Time
+	submitted  time.Time
+	presented  time.Time
+	imageIndex int
+	recreated  bool
+}
+
+// startFrameStats opens the stats export when stats_export is configured.
+func (a *VulkanApp) startFrameStats() {
+	if a.cfg.statsExportPath == "" {
+		return
+	}
+	w, err := newFrameStatsWriter(a.cfg.statsExportPath, time.Now())
+	if err != nil {
+		log.Printf("stats: %v", err)
+		return
+	}
+	a.statsWriter = w
+	log.Printf("stats: writing per-frame records to %s", a.cfg.statsExportPath)
+}
+
+// writeFrameStats records the main-loop iteration that began at start and slept for sleep,
+// then clears the stamps for the next frame.
+func (a *VulkanApp) writeFrameStats(start time.Time, sleep time.Duration) {
+	st := a.frameStamps
+	a.frameStamps = frameStamps{}
+	if a.statsWriter == nil {
+		return
+	}
+	w := a.statsWriter
+	rec := frameRecord{
+		Frame:      a.statsFrames,
+		Presented:  !st.presented.IsZero(),
+		StartNS:    w.since(start),
+		AcquireNS:  w.since(st.acquired),
+		RecordNS:   w.since(st.recorded),
+		SubmitNS:   w.since(st.submitted),
+		PresentNS:  w.since(st.presented),
+		EndNS:      w.since(time.Now()),
+		ImageIndex: st.imageIndex,
+		SleepNS:    int64(sleep),
+		GPUNS:      int64(a.gpuTimes.total),
+		Recreated:  st.recreated,
+		Recreates:  a.swapchainRecreates,
+	}
+	a.statsFrames++
+	if err := w.write(rec); err != nil {
+		log.Printf("stats: %v (export stopped)", err)
+		a.stopFrameStats()
+	}
+}
+
+// stopFrameStats closes the stats export.
+func (a *VulkanApp) stopFrameStats() {
+	if a.statsWriter == nil {
+		return
+	}
+	if err := a.statsWriter.Close(); err != nil {
+		log.Printf("stats: %v", err)
+	}
+	a.statsWriter = nil
+}
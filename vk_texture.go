@@ -9,67 +9,255 @@ package main
 import "C"
 
 import (
+	"bytes"
 	"fmt"
+	"image"
+	"image/draw"
+	_ "image/jpeg"
+	_ "image/png"
 	"log"
+	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"unsafe"
 
 	"github.com/vulkan-go/vulkan"
 )
 
-// createTextureImage uploads the embedded vkcube texture (or fallback) into a sampled image.
+// textureSource is a CPU-side texture ready for upload. Every layer carries the same mip chain,
+// with level 0 at full resolution.
+type textureSource struct {
+	format vulkan.Format
+	width  uint32
+	height uint32
+	layers [][][]byte
+}
+
+// mipLevels reports how many mip levels each layer provides.
+func (t textureSource) mipLevels() uint32 {
+	if len(t.layers) == 0 {
+		return 0
+	}
+	return uint32(len(t.layers[0]))
+}
+
+// levelSize returns the texel dimensions of a mip level, clamped to one texel.
+func (t textureSource) levelSize(level int) (uint32, uint32) {
+	w := t.width >> uint(level)
+	h := t.height >> uint(level)
+	if w == 0 {
+		w = 1
+	}
+	if h == 0 {
+		h = 1
+	}
+	return w, h
+}
+
+// rgbaTextureSource wraps a single RGBA8 sRGB image without mips.
+func rgbaTextureSource(width, height uint32, pixels []byte) textureSource {
+	return textureSource{
+		format: vulkan.FormatR8g8b8a8Srgb,
+		width:  width,
+		height: height,
+		layers: [][][]byte{{pixels}},
+	}
+}
+
+// createTextureImage uploads the configured texture (or the embedded vkcube texture/fallback) into a sampled image.
 func (a *VulkanApp) createTextureImage() error {
+	src := a.loadTextureSource()
+	image, memory, err := a.uploadTexture(src, 0)
+	if err != nil {
+		return err
+	}
+	a.textureImage = image
+	a.textureImageMemory = memory
+	a.textureFormat = src.format
+	a.textureMipLevels = src.mipLevels()
+	a.textureLayers = uint32(len(src.layers))
+	return nil
+}
+
+// loadTextureSource resolves the per-face textures configured via `face_textures:`, the single `texture:`,
+// or the mesh's diffuse map, falling back to the embedded vkcube image.
+func (a *VulkanApp) loadTextureSource() textureSource {
+	if len(a.cfg.faceTexturePaths) > 0 {
+		src, err := a.loadFaceTextures(a.cfg.faceTexturePaths)
+		if err == nil {
+			log.Printf("texture: loaded %d face textures (format=%v %dx%d mips=%d)", len(src.layers), src.format, src.width, src.height, src.mipLevels())
+			return src
+		}
+		log.Printf("load face textures failed, using single texture: %v", err)
+	}
+	texturePath := a.cfg.texturePath
+	if texturePath == "" {
+		texturePath = a.mesh.texturePath
+	}
+	if texturePath != "" {
+		src, err := loadTextureFile(texturePath)
+		if err == nil {
+			src, err = a.resolveTextureFormat(src)
+		}
+		if err == nil {
+			log.Printf("texture: loaded %s (format=%v %dx%d mips=%d)", texturePath, src.format, src.width, src.height, src.mipLevels())
+			return src
+		}
+		log.Printf("load texture %s failed, using embedded vkcube texture: %v", texturePath, err)
+	}
 	texWidth, texHeight, pixels, err := loadVkcubeTexture()
 	if err != nil {
 		log.Printf("load embedded vkcube texture failed, using fallback checker: %v", err)
 		texWidth, texHeight, pixels = fallbackCheckerTexture()
 	}
+	return rgbaTextureSource(texWidth, texHeight, pixels)
+}
 
-	imageSize := vulkan.DeviceSize(len(pixels))
+// loadFaceTextures stacks one image per cube face into the layers of a texture array.
+// Every face must share the format, size, and mip count of the first.
+func (a *VulkanApp) loadFaceTextures(paths []string) (textureSource, error) {
+	var out textureSource
+	for i, path := range paths {
+		src, err := loadTextureFile(path)
+		if err == nil {
+			src, err = a.resolveTextureFormat(src)
+		}
+		if err != nil {
+			return textureSource{}, fmt.Errorf("face %d (%s): %w", i, path, err)
+		}
+		if len(src.layers) != 1 {
+			return textureSource{}, fmt.Errorf("face %d (%s): expected a single layer, got %d", i, path, len(src.layers))
+		}
+		if i == 0 {
+			out = textureSource{format: src.format, width: src.width, height: src.height}
+		} else if src.format != out.format || src.width != out.width || src.height != out.height || src.mipLevels() != out.mipLevels() {
+			return textureSource{}, fmt.Errorf("face %d (%s): %v %dx%d mips=%d does not match face 0 (%v %dx%d mips=%d)",
+				i, path, src.format, src.width, src.height, src.mipLevels(), out.format, out.width, out.height, out.mipLevels())
+		}
+		out.layers = append(out.layers, src.layers[0])
+	}
+	return out, nil
+}
+
+// resolveTextureFormat keeps the source format when the device can sample it, otherwise decodes to RGBA8 on the CPU.
+func (a *VulkanApp) resolveTextureFormat(src textureSource) (textureSource, error) {
+	features := vulkan.FormatFeatureFlags(vulkan.FormatFeatureSampledImageBit | vulkan.FormatFeatureSampledImageFilterLinearBit | vulkan.FormatFeatureTransferDstBit)
+	if _, err := a.findSupportedFormat([]vulkan.Format{src.format}, vulkan.ImageTilingOptimal, features); err == nil {
+		return src, nil
+	}
+	bf, ok := blockFormats[src.format]
+	if !ok || bf.decode == nil {
+		return textureSource{}, fmt.Errorf("format %v not supported by the device and no CPU decoder is available", src.format)
+	}
+	log.Printf("texture: format %v not supported by the device, decoding to RGBA8 on the CPU", src.format)
+	out := textureSource{
+		format: vulkan.FormatR8g8b8a8Unorm,
+		width:  src.width,
+		height: src.height,
+		layers: make([][][]byte, len(src.layers)),
+	}
+	if bf.srgb {
+		out.format = vulkan.FormatR8g8b8a8Srgb
+	}
+	for layer, levels := range src.layers {
+		out.layers[layer] = make([][]byte, len(levels))
+		for level, data := range levels {
+			w, h := src.levelSize(level)
+			rgba, err := bf.decode(data, w, h)
+			if err != nil {
+				return textureSource{}, fmt.Errorf("decode layer %d level %d: %w", layer, level, err)
+			}
+			out.layers[layer][level] = rgba
+		}
+	}
+	return out, nil
+}
+
+// uploadTexture copies every layer and mip level through a staging buffer into a device-local image
+// left in SHADER_READ_ONLY_OPTIMAL layout.
+func (a *VulkanApp) uploadTexture(src textureSource, flags vulkan.ImageCreateFlags) (vulkan.Image, vulkan.DeviceMemory, error) {
+	mipLevels := src.mipLevels()
+	layerCount := uint32(len(src.layers))
+	if layerCount == 0 || mipLevels == 0 {
+		return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), fmt.Errorf("texture has no image data")
+	}
+
+	// Lay out each subresource at a 16-byte aligned offset, which satisfies every block size we accept.
+	var regions []vulkan.BufferImageCopy
+	var offsets []int
+	total := 0
+	for level := 0; level < int(mipLevels); level++ {
+		w, h := src.levelSize(level)
+		for layer := range src.layers {
+			if len(src.layers[layer]) != int(mipLevels) {
+				return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), fmt.Errorf("texture layer %d has %d mip levels, want %d", layer, len(src.layers[layer]), mipLevels)
+			}
+			total = (total + 15) &^ 15
+			offsets = append(offsets, total)
+			regions = append(regions, vulkan.BufferImageCopy{
+				BufferOffset: vulkan.DeviceSize(total),
+				ImageSubresource: vulkan.ImageSubresourceLayers{
+					AspectMask:     vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit),
+					MipLevel:       uint32(level),
+					BaseArrayLayer: uint32(layer),
+					LayerCount:     1,
+				},
+				ImageExtent: vulkan.Extent3D{Width: w, Height: h, Depth: 1},
+			})
+			total += len(src.layers[layer][level])
+		}
+	}
+
+	imageSize := vulkan.DeviceSize(total)
 	stageBuf, stageMem, err := a.createBuffer(imageSize, vulkan.BufferUsageFlags(vulkan.BufferUsageTransferSrcBit), vulkan.MemoryPropertyHostVisibleBit|vulkan.MemoryPropertyHostCoherentBit)
 	if err != nil {
-		return fmt.Errorf("create staging buffer: %w", err)
+		return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), fmt.Errorf("create staging buffer: %w", err)
 	}
 	defer vulkan.DestroyBuffer(a.device, stageBuf, nil)
-	defer vulkan.FreeMemory(a.device, stageMem, nil)
+	defer a.freeMemory(stageMem)
 
 	var data unsafe.Pointer
 	if res := vulkan.MapMemory(a.device, stageMem, 0, imageSize, 0, &data); res != vulkan.Success {
-		return fmt.Errorf("map staging buffer: %w", vulkan.Error(res))
+		return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), fmt.Errorf("map staging buffer: %w", vulkan.Error(res))
 	}
 	dst := (*[1 << 30]byte)(data)[:imageSize:imageSize]
-	copy(dst, pixels)
+	i := 0
+	for level := 0; level < int(mipLevels); level++ {
+		for layer := range src.layers {
+			copy(dst[offsets[i]:], src.layers[layer][level])
+			i++
+		}
+	}
 	vulkan.UnmapMemory(a.device, stageMem)
 
-	image, memory, err := a.createImage(texWidth, texHeight, vulkan.FormatR8g8b8a8Srgb, vulkan.ImageTilingOptimal, vulkan.ImageUsageFlags(vulkan.ImageUsageTransferDstBit|vulkan.ImageUsageSampledBit), vulkan.MemoryPropertyDeviceLocalBit)
+	image, memory, err := a.createImageLevels(src.width, src.height, mipLevels, layerCount, flags, src.format, vulkan.ImageTilingOptimal, vulkan.ImageUsageFlags(vulkan.ImageUsageTransferDstBit|vulkan.ImageUsageSampledBit), vulkan.MemoryPropertyDeviceLocalBit)
 	if err != nil {
-		return fmt.Errorf("create texture image: %w", err)
+		return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), fmt.Errorf("create texture image: %w", err)
 	}
 
-	if err := a.transitionImageLayout(image, vulkan.FormatR8g8b8a8Srgb, vulkan.ImageLayoutUndefined, vulkan.ImageLayoutTransferDstOptimal); err != nil {
+	if err := a.transitionImageLayout(image, src.format, vulkan.ImageLayoutUndefined, vulkan.ImageLayoutTransferDstOptimal, mipLevels, layerCount); err != nil {
 		vulkan.DestroyImage(a.device, image, nil)
-		vulkan.FreeMemory(a.device, memory, nil)
-		return err
+		a.freeMemory(memory)
+		return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), err
 	}
-	if err := a.copyBufferToImage(stageBuf, image, texWidth, texHeight); err != nil {
+	if err := a.copyBufferToImage(stageBuf, image, regions); err != nil {
 		vulkan.DestroyImage(a.device, image, nil)
-		vulkan.FreeMemory(a.device, memory, nil)
-		return err
+		a.freeMemory(memory)
+		return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), err
 	}
-	if err := a.transitionImageLayout(image, vulkan.FormatR8g8b8a8Srgb, vulkan.ImageLayoutTransferDstOptimal, vulkan.ImageLayoutShaderReadOnlyOptimal); err != nil {
+	if err := a.transitionImageLayout(image, src.format, vulkan.ImageLayoutTransferDstOptimal, vulkan.ImageLayoutShaderReadOnlyOptimal, mipLevels, layerCount); err != nil {
 		vulkan.DestroyImage(a.device, image, nil)
-		vulkan.FreeMemory(a.device, memory, nil)
-		return err
+		a.freeMemory(memory)
+		return vulkan.Image(vulkan.NullHandle), vulkan.DeviceMemory(vulkan.NullHandle), err
 	}
-
-	a.textureImage = image
-	a.textureImageMemory = memory
-	return nil
+	return image, memory, nil
 }
 
-// createTextureImageView wraps the texture image in a shader-visible color view.
+// createTextureImageView wraps the texture image in a 2D array view; the fragment shader picks the layer
+// from the face index, and single-layer textures clamp every face to layer 0.
 func (a *VulkanApp) createTextureImageView() error {
-	view, err := a.createImageView(a.textureImage, vulkan.FormatR8g8b8a8Srgb, vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit))
+	view, err := a.createImageViewLevels(a.textureImage, a.textureFormat, vulkan.ImageAspectFlags(vulkan.ImageAspectColorBit), vulkan.ImageViewType2dArray, a.textureMipLevels, a.textureLayers)
 	if err != nil {
 		return err
 	}
@@ -77,15 +265,30 @@ func (a *VulkanApp) createTextureImageView() error {
 	return nil
 }
 
-// createTextureSampler sets up a repeat, linear-filtered sampler for the cube texture.
+// createTextureSampler sets up a repeat, trilinear-filtered sampler for the cube texture.
 func (a *VulkanApp) createTextureSampler() error {
+	sampler, err := a.createSampler(vulkan.SamplerAddressModeRepeat, float32(a.textureMipLevels))
+	if err != nil {
+		return err
+	}
+	a.textureSampler = sampler
+	return nil
+}
+
+// createSampler builds a trilinear-filtered sampler with the given address mode on all axes.
+func (a *VulkanApp) createSampler(addressMode vulkan.SamplerAddressMode, maxLod float32) (vulkan.Sampler, error) {
+	return a.createFilteredSampler(vulkan.FilterLinear, addressMode, maxLod)
+}
+
+// createFilteredSampler builds a sampler with the given min/mag filter and address mode.
+func (a *VulkanApp) createFilteredSampler(filter vulkan.Filter, addressMode vulkan.SamplerAddressMode, maxLod float32) (vulkan.Sampler, error) {
 	samplerInfo := vulkan.SamplerCreateInfo{
 		SType:                   vulkan.StructureTypeSamplerCreateInfo,
-		MagFilter:               vulkan.FilterLinear,
-		MinFilter:               vulkan.FilterLinear,
-		AddressModeU:            vulkan.SamplerAddressModeRepeat,
-		AddressModeV:            vulkan.SamplerAddressModeRepeat,
-		AddressModeW:            vulkan.SamplerAddressModeRepeat,
+		MagFilter:               filter,
+		MinFilter:               filter,
+		AddressModeU:            addressMode,
+		AddressModeV:            addressMode,
+		AddressModeW:            addressMode,
 		AnisotropyEnable:        vulkan.False,
 		MaxAnisotropy:           1.0,
 		BorderColor:             vulkan.BorderColorIntOpaqueBlack,
@@ -95,20 +298,19 @@ func (a *VulkanApp) createTextureSampler() error {
 		MipmapMode:              vulkan.SamplerMipmapModeLinear,
 		MipLodBias:              0,
 		MinLod:                  0,
-		MaxLod:                  0,
+		MaxLod:                  maxLod,
 	}
 	var zero vulkan.Sampler
 	samplerOut := (*vulkan.Sampler)(C.malloc(C.size_t(unsafe.Sizeof(zero))))
 	if samplerOut == nil {
-		return fmt.Errorf("allocate sampler handle")
+		return vulkan.Sampler(vulkan.NullHandle), fmt.Errorf("allocate sampler handle")
 	}
 	defer C.free(unsafe.Pointer(samplerOut))
 
 	if res := vulkan.CreateSampler(a.device, &samplerInfo, nil, samplerOut); res != vulkan.Success {
-		return fmt.Errorf("create sampler: %w", vulkan.Error(res))
+		return vulkan.Sampler(vulkan.NullHandle), fmt.Errorf("create sampler: %w", vulkan.Error(res))
 	}
-	a.textureSampler = *samplerOut
-	return nil
+	return *samplerOut, nil
 }
 
 // loadVkcubeTexture parses the embedded Lunarg PPM payload into RGBA bytes.
@@ -116,7 +318,56 @@ func loadVkcubeTexture() (uint32, uint32, []byte, error) {
 	if len(lunargPPM) == 0 {
 		return 0, 0, nil, fmt.Errorf("embedded texture payload missing")
 	}
-	w, h, rgb, err := parsePPM(lunargPPM)
+	return decodePPMToRGBA(lunargPPM)
+}
+
+// loadTextureFile loads a KTX2 container or a PNG/JPEG/PPM image from disk.
+func loadTextureFile(path string) (textureSource, error) {
+	if strings.EqualFold(filepath.Ext(path), ".ktx2") {
+		ktx, err := loadKTX2File(path)
+		if err != nil {
+			return textureSource{}, err
+		}
+		return textureFromKTX2(ktx)
+	}
+	w, h, pixels, err := loadImageFile(path)
+	if err != nil {
+		return textureSource{}, err
+	}
+	return rgbaTextureSource(w, h, pixels), nil
+}
+
+// loadImageFile decodes a PNG, JPEG, or P6 PPM file into RGBA8 pixels.
+func loadImageFile(path string) (uint32, uint32, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("read image: %w", err)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".ppm") {
+		return decodePPMToRGBA(data)
+	}
+	w, h, pixels, err := decodeImageRGBA(data)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	return w, h, pixels, nil
+}
+
+// decodeImageRGBA decodes an in-memory PNG or JPEG into RGBA8 pixels.
+func decodeImageRGBA(data []byte) (uint32, uint32, []byte, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return uint32(bounds.Dx()), uint32(bounds.Dy()), rgba.Pix, nil
+}
+
+// decodePPMToRGBA parses a P6 PPM payload and expands it to opaque RGBA bytes.
+func decodePPMToRGBA(data []byte) (uint32, uint32, []byte, error) {
+	w, h, rgb, err := parsePPM(data)
 	if err != nil {
 		return 0, 0, nil, err
 	}
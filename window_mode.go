@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/vulkan-go/glfw/v3.3/glfw"
+)
+
+// windowMode is how the window occupies the screen.
+type windowMode int
+
+const (
+	windowWindowed   windowMode = iota
+	windowBorderless            // fullscreen at the monitor's current video mode, no mode switch
+	windowFullscreen            // exclusive fullscreen at the configured video mode
+)
+
+func (m windowMode) String() string {
+	switch m {
+	case windowBorderless:
+		return "borderless"
+	case windowFullscreen:
+		return "fullscreen"
+	}
+	return "windowed"
+}
+
+// parseWindowMode accepts windowed, borderless, or fullscreen.
+func parseWindowMode(s string) (windowMode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "windowed":
+		return windowWindowed, nil
+	case "borderless":
+		return windowBorderless, nil
+	case "fullscreen", "exclusive":
+		return windowFullscreen, nil
+	}
+	return windowWindowed, fmt.Errorf("unknown window mode %q (want windowed, borderless, or fullscreen)", s)
+}
+
+// windowConfig holds the `window:` config block.
+type windowConfig struct {
+	mode        windowMode
+	toggleMode  windowMode // what the fullscreen toggle switches to from windowed
+	monitor     int        // 0 follows the window (primary at startup), 1-n picks from GLFW's list
+	width       int        // windowed client size
+	height      int
+	videoWidth  int // exclusive video mode; 0 keeps the monitor's current one
+	videoHeight int
+	refreshRate int
+}
+
+// defaultWindowConfig is an 800x600 window that toggles to borderless fullscreen.
+func defaultWindowConfig() windowConfig {
+	return windowConfig{mode: windowWindowed, toggleMode: windowBorderless, width: 800, height: 600}
+}
+
+// windowState switches the window between modes and remembers the windowed geometry so
+// leaving fullscreen puts the window back where it was.
+type windowState struct {
+	cfg     windowConfig
+	mode    windowMode
+	monitor int
+	x, y    int
+	width   int
+	height  int
+	placed  bool // x/y hold a real windowed position
+}
+
+// newWindowState starts from the configured windowed size; the window itself is still windowed.
+func newWindowState(cfg windowConfig) *windowState {
+	return &windowState{cfg: cfg, monitor: cfg.monitor, width: cfg.width, height: cfg.height}
+}
+
+// pickMonitor returns the configured monitor, else the one holding the window's center, else
+// the primary monitor.
+func (s *windowState) pickMonitor(w *glfw.Window) *glfw.Monitor {
+	monitors := glfw.GetMonitors()
+	if s.monitor > 0 {
+		if s.monitor <= len(monitors) {
+			return monitors[s.monitor-1]
+		}
+		log.Printf("Window: monitor %d not connected (%d available); using primary", s.monitor, len(monitors))
+		return glfw.GetPrimaryMonitor()
+	}
+	if m := w.GetMonitor(); m != nil {
+		return m
+	}
+	x, y := w.GetPos()
+	width, height := w.GetSize()
+	cx, cy := x+width/2, y+height/2
+	for _, m := range monitors {
+		mx, my := m.GetPos()
+		vm := m.GetVideoMode()
+		if vm != nil && cx >= mx && cx < mx+vm.Width && cy >= my && cy < my+vm.Height {
+			return m
+		}
+	}
+	return glfw.GetPrimaryMonitor()
+}
+
+// videoMode picks the exclusive mode closest to the configured size and refresh rate.
+func (s *windowState) videoMode(m *glfw.Monitor) *glfw.VidMode {
+	current := m.GetVideoMode()
+	if s.cfg.videoWidth == 0 || s.cfg.videoHeight == 0 {
+		return current
+	}
+	best := current
+	bestScore := -1
+	for _, vm := range m.GetVideoModes() {
+		score := abs(vm.Width-s.cfg.videoWidth) + abs(vm.Height-s.cfg.videoHeight)
+		if s.cfg.refreshRate > 0 {
+			score += abs(vm.RefreshRate - s.cfg.refreshRate)
+		} else if current != nil {
+			score += abs(vm.RefreshRate - current.RefreshRate)
+		}
+		if bestScore < 0 || score < bestScore {
+			best, bestScore = vm, score
+		}
+	}
+	return best
+}
+
+// apply moves the window into mode, saving the windowed geometry when leaving windowed mode.
+func (s *windowState) apply(w *glfw.Window, mode windowMode) {
+	if mode == windowWindowed && s.mode == windowWindowed {
+		return
+	}
+	if s.mode == windowWindowed && mode != windowWindowed {
+		s.x, s.y = w.GetPos()
+		s.width, s.height = w.GetSize()
+		s.placed = true
+	}
+	switch mode {
+	case windowWindowed:
+		if !s.placed {
+			// Never been windowed at a known spot: center on the monitor we're leaving.
+			m := s.pickMonitor(w)
+			mx, my := m.GetPos()
+			if vm := m.GetVideoMode(); vm != nil {
+				s.x, s.y = mx+(vm.Width-s.width)/2, my+(vm.Height-s.height)/2
+			}
+			s.placed = true
+		}
+		w.SetMonitor(nil, s.x, s.y, s.width, s.height, glfw.DontCare)
+		log.Printf("Window: windowed %dx%d at %d,%d", s.width, s.height, s.x, s.y)
+	case windowBorderless, windowFullscreen:
+		m := s.pickMonitor(w)
+		vm := m.GetVideoMode()
+		if mode == windowFullscreen {
+			vm = s.videoMode(m)
+		}
+		if vm == nil {
+			log.Printf("Window: monitor %q reports no video mode; staying %s", m.GetName(), s.mode)
+			return
+		}
+		w.SetMonitor(m, 0, 0, vm.Width, vm.Height, vm.RefreshRate)
+		log.Printf("Window: %s on %q at %dx%d@%dHz", mode, m.GetName(), vm.Width, vm.Height, vm.RefreshRate)
+	}
+	s.mode = mode
+}
+
+// toggle switches between windowed and the configured fullscreen mode.
+func (s *windowState) toggle(w *glfw.Window) {
+	if s.mode != windowWindowed {
+		s.apply(w, windowWindowed)
+		return
+	}
+	s.apply(w, s.cfg.toggleMode)
+}
+
+// nextMonitor moves a fullscreen window to the next connected monitor; in windowed mode it
+// only selects the monitor the next toggle uses.
+func (s *windowState) nextMonitor(w *glfw.Window) {
+	monitors := glfw.GetMonitors()
+	if len(monitors) == 0 {
+		return
+	}
+	current := s.pickMonitor(w)
+	next := 0
+	for i, m := range monitors {
+		if *m == *current {
+			next = (i + 1) % len(monitors)
+		}
+	}
+	s.monitor = next + 1
+	log.Printf("Window: monitor %d of %d (%q)", s.monitor, len(monitors), monitors[next].GetName())
+	if s.mode != windowWindowed {
+		s.apply(w, s.mode)
+	}
+}
+
+// abs returns |v|.
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}